@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/diff"
+	"github.com/hex0punk/wally/reporter"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	diffFormat string
+	diffOutput string
+	failOn     []string
+)
+
+var diffFormats = []string{"text", "json", "markdown"}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "Compares the json output of two wally runs",
+	Long: `Compares the json output of two wally runs and reports added and removed routes,
+changes in resolved params, new and removed call paths, and changes in recoverability`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("diff requires two wally json files, got %d", len(args))
+		}
+		if diffFormat != "" && !slices.Contains(diffFormats, diffFormat) {
+			return fmt.Errorf("unsupported diff format %q. Supported: %s", diffFormat, strings.Join(diffFormats, ", "))
+		}
+		for _, kind := range failOn {
+			if !isChangeKind(kind) {
+				return fmt.Errorf("unsupported fail-on value %q. Supported: %s", kind, strings.Join(diff.ChangeKinds, ", "))
+			}
+		}
+		return nil
+	},
+	Run: diffResults,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.PersistentFlags().StringVar(&diffFormat, "format", "text", fmt.Sprintf("Output format. Supported: %s", strings.Join(diffFormats, ", ")))
+	diffCmd.PersistentFlags().StringVarP(&diffOutput, "out", "o", "", "Output to file path")
+	diffCmd.PersistentFlags().StringSliceVar(&failOn, "fail-on", []string{}, fmt.Sprintf("Comma separated list of changes that result in a non-zero exit code. Supported: %s", strings.Join(diff.ChangeKinds, ", ")))
+}
+
+func diffResults(cmd *cobra.Command, args []string) {
+	oldMatches, err := diff.Load(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	newMatches, err := diff.Load(args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := diff.Compare(oldMatches, newMatches)
+	if err := reporter.PrintDiff(report, diffFormat, diffOutput); err != nil {
+		log.Fatal(err)
+	}
+
+	var failed []string
+	for _, kind := range failOn {
+		if report.Has(kind) {
+			failed = append(failed, kind)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "wally diff found changes: %s\n", strings.Join(failed, ", "))
+		os.Exit(1)
+	}
+}
+
+func isChangeKind(kind string) bool {
+	for _, k := range diff.ChangeKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
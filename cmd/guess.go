@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/guess"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/navigator"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+)
+
+var (
+	guessPaths  []string
+	guessOutput string
+	guessAll    bool
+)
+
+var guessCmd = &cobra.Command{
+	Use:   "guess",
+	Short: "Proposes indicators for functions that look like they register routes",
+	Long: `Finds functions whose signature looks like a handler, such as func(http.ResponseWriter, *http.Request),
+func(*gin.Context), func(echo.Context) error, http.Handler values and services with gRPC-style methods, and proposes indicators
+for the functions they are passed to. The output is a config file that can be reviewed and passed to wally map`,
+	Run: guessRoutes,
+}
+
+func init() {
+	rootCmd.AddCommand(guessCmd)
+	guessCmd.PersistentFlags().StringSliceVarP(&guessPaths, "paths", "p", []string{"./..."}, "The comma separated package paths to target. Use ./.. for current directory and subdirectories")
+	guessCmd.PersistentFlags().StringVarP(&guessOutput, "out", "o", "", "Output to file path")
+	guessCmd.PersistentFlags().BoolVar(&guessAll, "all", false, "Include functions already matched by the stock indicators")
+}
+
+func guessRoutes(cmd *cobra.Command, args []string) {
+	pkgs := navigator.LoadPackages(guessPaths)
+
+	var candidates []*guess.Candidate
+	for _, c := range guess.Guess(pkgs, indicator.InitIndicators(nil, false)) {
+		if guessAll || !c.Known {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No functions registering handlers found")
+		return
+	}
+
+	out, err := guess.Proposal(candidates)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if guessOutput == "" {
+		fmt.Print(string(out))
+		return
+	}
+	if err := os.WriteFile(guessOutput, out, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/hex0punk/wally/indicator"
 	"github.com/hex0punk/wally/navigator"
+	"github.com/hex0punk/wally/policy"
 	"github.com/hex0punk/wally/reporter"
 	"github.com/hex0punk/wally/server"
 	"github.com/hex0punk/wally/wallylib/callmapper"
@@ -27,6 +28,8 @@ var (
 	printNodes         bool
 	format             string
 	outputFile         string
+	pathsOutputFile    string
+	maxPathsInReport   int
 	serverGraph        bool
 	skipDefault        bool
 	limiterMode        int
@@ -35,8 +38,14 @@ var (
 	skipClosures       bool
 	moduleOnly         bool
 	simplify           bool
+	entryRoots         bool
 	excludePkgs        []string
 	excluseByPosSuffix []string
+	baselineFile       string
+	writeBaselineFile  string
+	showSuppressed     bool
+	authMiddleware     []string
+	unauthenticated    bool
 )
 
 // mapCmd represents the map command
@@ -45,8 +54,8 @@ var mapCmd = &cobra.Command{
 	Short: "Get list a list of all routes",
 	Long:  `Get list a list of all routes with resolved values as possible for params, along with enclosing functions"`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if format != "" && format != "json" {
-			return fmt.Errorf("invalid output type: %q", format)
+		if err := validateFormat(format); err != nil {
+			return err
 		}
 
 		searchAlg = strings.ToLower(searchAlg)
@@ -81,26 +90,42 @@ func init() {
 	mapCmd.PersistentFlags().BoolVar(&skipClosures, "skip-closures", false, "Skip closure edges which can lead to innacurate results")
 	mapCmd.PersistentFlags().BoolVar(&moduleOnly, "module-only", true, "Filter call paths by the match module.")
 	mapCmd.PersistentFlags().BoolVarP(&simplify, "simple", "s", false, "Simple output focuses on function signatures rather than sites")
+	mapCmd.PersistentFlags().BoolVar(&entryRoots, "entry-roots", false, "End call paths at functions run by CLI commands, scheduled jobs and workers. Only works with --ssa")
 
 	mapCmd.PersistentFlags().StringSliceVarP(&paths, "paths", "p", paths, "The comma separated package paths to target. Use ./.. for current directory and subdirectories")
-	mapCmd.PersistentFlags().StringVarP(&graph, "graph", "g", "", "Path for optional PNG graph output. Only works with --ssa")
+	mapCmd.PersistentFlags().StringVarP(&graph, "graph", "g", "", "Path for optional graph output. Supported extensions: .dot, .mmd, .graphml, .gexf, .png and .xdot (the latter two require cgo). Only works with --ssa")
 	mapCmd.PersistentFlags().StringVar(&searchAlg, "search-alg", "bfs", "Search algorithm used for mapping callgraph (dfs or bfs)")
 	mapCmd.PersistentFlags().BoolVar(&runSSA, "ssa", false, "whether to run some checks using SSA")
 	mapCmd.PersistentFlags().StringVarP(&filter, "filter", "f", "", "Filter string for call graph search. Setting a non empty filter sets module-only to false")
 	mapCmd.PersistentFlags().IntVar(&maxFuncs, "max-funcs", 0, "Limit the max number of nodes or functions per call path")
 	mapCmd.PersistentFlags().IntVar(&maxPaths, "max-paths", 0, "Max paths per node. This helps when wally encounters recursive calls")
 	mapCmd.PersistentFlags().BoolVar(&printNodes, "print-nodes", false, "Print the position of call graph paths rather than node")
-	mapCmd.PersistentFlags().StringVar(&format, "format", "", "Output format. Supported: json, csv")
+	mapCmd.PersistentFlags().StringVar(&format, "format", "", fmt.Sprintf("Output format. Supported: %s", strings.Join(outputFormats, ", ")))
 	mapCmd.PersistentFlags().StringVarP(&outputFile, "out", "o", "", "Output to file path")
+	mapCmd.PersistentFlags().StringVar(&pathsOutputFile, "paths-out", "", "Optional file path for a table with one row per call path. Only used with csv-matches and tsv-matches formats")
+	mapCmd.PersistentFlags().IntVar(&maxPathsInReport, "max-paths-in-report", 0, "Max call paths per match included in markdown reports. Paths are still solved, but not printed")
 
 	mapCmd.PersistentFlags().StringSliceVar(&excludePkgs, "exclude-pkg", []string{}, "Comma separated list of packages to exclude")
 	mapCmd.PersistentFlags().StringSliceVar(&excluseByPosSuffix, "exclude-pos", []string{}, "Comma separated list of position prefixes used for filtering the selected function call matches")
 
+	mapCmd.PersistentFlags().StringVar(&baselineFile, "baseline", "", "Baseline file with accepted matches, which are suppressed from results")
+	mapCmd.PersistentFlags().StringVar(&writeBaselineFile, "write-baseline", "", "Write all matches not suppressed with a wally:ignore comment to a baseline file")
+	mapCmd.PersistentFlags().BoolVar(&showSuppressed, "show-suppressed", false, "Include matches suppressed via wally:ignore comments or a baseline in results")
+
+	mapCmd.PersistentFlags().StringSliceVar(&authMiddleware, "auth-middleware", []string{}, "Comma separated list of auth middleware functions (i.e. auth.Middleware), in addition to authMiddleware in the config file")
+	mapCmd.PersistentFlags().BoolVar(&unauthenticated, "unauthenticated-only", false, "Only report routes not wrapped by any auth middleware")
+
 	mapCmd.PersistentFlags().BoolVar(&serverGraph, "server", false, "Starts a server on port 1984 with output graph")
 }
 
 func mapRoutes(cmd *cobra.Command, args []string) {
 	initConfig()
+	if err := policy.Compile(wallyConfig.Policies); err != nil {
+		log.Fatal(err)
+	}
+	if err := indicator.ValidateIndicators(wallyConfig.Indicators, skipDefault); err != nil {
+		log.Fatal(err)
+	}
 
 	indicators := indicator.InitIndicators(wallyConfig.Indicators, skipDefault)
 	nav := navigator.NewNavigator(verbose, indicators)
@@ -111,9 +136,13 @@ func mapRoutes(cmd *cobra.Command, args []string) {
 		PosSuffixes: excluseByPosSuffix,
 	}
 
+	setupSuppressions(nav)
+	setupAuth(nav, wallyConfig.AuthMiddleware)
+
 	nav.Logger.Info("Running mapper", "indicators", len(indicators))
 
 	nav.MapRoutes(paths)
+	writeBaseline(nav)
 
 	if len(nav.RouteMatches) == 0 {
 		fmt.Println("No matches found")
@@ -131,28 +160,103 @@ func mapRoutes(cmd *cobra.Command, args []string) {
 			SkipClosures: skipClosures,
 			ModuleOnly:   moduleOnly,
 			Simplify:     simplify,
+			EntryRoots:   entryRoots,
 		}
 		nav.Logger.Info("Solving call paths for matches", "matches", len(nav.RouteMatches))
 		nav.SolveCallPaths(mapperOptions)
 	}
+
+	violations := evaluatePolicies(nav)
+
 	nav.Logger.Info("Printing results")
-	nav.PrintResults(format, outputFile)
+	nav.PrintResults(format, outputFile, reporterOptions())
 
 	if runSSA && graph != "" {
 		nav.Logger.Info("Generating graph", "graph filename", graph)
-		reporter.GenerateGraph(nav.RouteMatches, graph)
+		if err := reporter.GenerateGraph(nav.RouteMatches, graph); err != nil {
+			nav.Logger.Error("Error generating graph", "error", err.Error())
+		}
 	}
 
 	if serverGraph {
 		server.ServerCosmograph(reporter.GetJson(nav.RouteMatches), 1984)
 	}
+
+	if violations > 0 {
+		fmt.Fprintf(os.Stderr, "Found %d policy violations\n", violations)
+		os.Exit(1)
+	}
+}
+
+func evaluatePolicies(nav *navigator.Navigator) int {
+	if len(wallyConfig.Policies) == 0 {
+		return 0
+	}
+	for _, p := range wallyConfig.Policies {
+		if p.NeedsPaths() && !runSSA {
+			nav.Logger.Warn("Policy has call path rules, which are only evaluated with --ssa", "policy", p.Id)
+		}
+	}
+	nav.Logger.Info("Evaluating policies", "policies", len(wallyConfig.Policies))
+	return policy.Evaluate(wallyConfig.Policies, nav.RouteMatches, runSSA)
+}
+
+func setupSuppressions(nav *navigator.Navigator) {
+	nav.ShowSuppressed = showSuppressed
+	if baselineFile == "" {
+		return
+	}
+	baseline, err := navigator.LoadBaseline(baselineFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	nav.Baseline = baseline
+}
+
+func setupAuth(nav *navigator.Navigator, configured []string) {
+	nav.AuthMiddleware = append(append([]string{}, configured...), authMiddleware...)
+	nav.UnauthenticatedOnly = unauthenticated
+	if unauthenticated && len(nav.AuthMiddleware) == 0 {
+		nav.Logger.Warn("No auth middleware configured with authMiddleware or --auth-middleware. All routes will be reported as unauthenticated")
+	}
+}
+
+func writeBaseline(nav *navigator.Navigator) {
+	if writeBaselineFile == "" {
+		return
+	}
+	nav.Logger.Info("Writing baseline", "file", writeBaselineFile)
+	if err := nav.WriteBaseline(writeBaselineFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var outputFormats = []string{"json", "csv", "csv-matches", "tsv-matches", "sarif", "openapi", "html", "markdown", "neo4j", "egress", "sql"}
+
+func validateFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, f := range outputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output type: %q", format)
+}
+
+func reporterOptions() reporter.Options {
+	return reporter.Options{
+		PathsFile:        pathsOutputFile,
+		MaxPathsInReport: maxPathsInReport,
+	}
 }
 
 func initConfig() {
 	wallyConfig = WallyConfig{}
 	fmt.Println("Looking for config file in ", config)
 	if _, err := os.Stat(config); os.IsNotExist(err) {
-		fmt.Println("Configuration file `%s` not found. Will run stock indicators only", config)
+		fmt.Printf("Configuration file `%s` not found. Will run stock indicators only\n", config)
 	} else {
 		data, err := os.ReadFile(config)
 		if err != nil {
@@ -161,7 +265,7 @@ func initConfig() {
 
 		err = yaml.Unmarshal([]byte(data), &wallyConfig)
 		if err != nil {
-			fmt.Println("Could not load configuration file: %s. Will run stock indicators only", err)
+			fmt.Printf("Could not load configuration file: %s. Will run stock indicators only\n", err)
 		}
 	}
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/policy"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -9,6 +10,9 @@ import (
 
 type WallyConfig struct {
 	Indicators []indicator.Indicator `yaml:"indicators"`
+	Policies   []policy.Policy       `yaml:"policies"`
+	// AuthMiddleware lists functions, as <package>.<function> or full names, that authenticate requests
+	AuthMiddleware []string `yaml:"authMiddleware"`
 }
 
 var (
@@ -8,6 +8,7 @@ import (
 	"github.com/hex0punk/wally/server"
 	"github.com/hex0punk/wally/wallylib/callmapper"
 	"github.com/spf13/cobra"
+	"log"
 	"strings"
 )
 
@@ -25,8 +26,8 @@ var funcCmd = &cobra.Command{
 	Long:  `Performs analysis given a single function"`,
 	Run:   searchFunc,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if format != "" && format != "json" {
-			return fmt.Errorf("invalid output type: %q", format)
+		if err := validateFormat(format); err != nil {
+			return err
 		}
 
 		searchAlg = strings.ToLower(searchAlg)
@@ -76,6 +77,14 @@ func searchFunc(cmd *cobra.Command, args []string) {
 	nav := navigator.NewNavigator(verbose, indicators)
 	nav.RunSSA = true
 	nav.CallgraphAlg = callgraphAlg
+	// Route registrations are resolved so that paths through handlers behind recovery middleware are recoverable
+	if config != "" {
+		initConfig()
+		if err := indicator.ValidateIndicators(wallyConfig.Indicators, skipDefault); err != nil {
+			log.Fatal(err)
+		}
+	}
+	nav.HandlerIndicators = indicator.InitIndicators(wallyConfig.Indicators, skipDefault)
 
 	mapperOptions := callmapper.Options{
 		Filter:       filter,
@@ -87,10 +96,15 @@ func searchFunc(cmd *cobra.Command, args []string) {
 		SkipClosures: skipClosures,
 		ModuleOnly:   moduleOnly,
 		Simplify:     simplify,
+		EntryRoots:   entryRoots,
 	}
 
+	setupSuppressions(nav)
+	setupAuth(nav, wallyConfig.AuthMiddleware)
+
 	nav.Logger.Info("Running mapper", "indicators", len(indicators))
 	nav.MapRoutes(paths)
+	writeBaseline(nav)
 
 	if len(nav.RouteMatches) == 0 {
 		fmt.Printf("No matches found for func %s in package %s\n", function, pkg)
@@ -100,11 +114,13 @@ func searchFunc(cmd *cobra.Command, args []string) {
 	nav.Logger.Info("Solving call paths for matches", "matches", len(nav.RouteMatches))
 	nav.SolveCallPaths(mapperOptions)
 
-	nav.PrintResults(format, outputFile)
+	nav.PrintResults(format, outputFile, reporterOptions())
 
 	if graph != "" {
 		nav.Logger.Info("Generating graph", "graph filename", graph)
-		reporter.GenerateGraph(nav.RouteMatches, graph)
+		if err := reporter.GenerateGraph(nav.RouteMatches, graph); err != nil {
+			nav.Logger.Error("Error generating graph", "error", err.Error())
+		}
 	}
 
 	if serverGraph {
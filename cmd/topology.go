@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/reporter"
+	"github.com/hex0punk/wally/topology"
+	"github.com/spf13/cobra"
+	"log"
+	"slices"
+	"strings"
+)
+
+var (
+	topologyFormat string
+	topologyOutput string
+	topologyHosts  []string
+)
+
+var topologyCmd = &cobra.Command{
+	Use:   "topology [name=]service.json...",
+	Short: "Links the json output of wally runs on several services into a service graph",
+	Long: `Links the outbound calls found in the json output of wally runs on several services to the routes of the
+other services, and writes a graph of which services call each other, with the endpoints called on each edge.
+Services are named after their main module unless a name is given, as in orders=orders.json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("topology requires at least one wally json file")
+		}
+		return nil
+	},
+	Run: buildTopology,
+}
+
+func init() {
+	rootCmd.AddCommand(topologyCmd)
+	topologyCmd.PersistentFlags().StringVar(&topologyFormat, "format", "json", "Output format. Supported: json, dot, graphml")
+	topologyCmd.PersistentFlags().StringVarP(&topologyOutput, "out", "o", "", "Output to file path")
+	topologyCmd.PersistentFlags().StringArrayVar(&topologyHosts, "host", nil, "Host a service is reached at, as name=host. Can be repeated")
+}
+
+func buildTopology(cmd *cobra.Command, args []string) {
+	var services []*topology.Service
+	files := make(map[string]string)
+	for _, arg := range args {
+		s, err := topology.Load(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Services of the same name would be merged in the graph
+		if file, ok := files[s.Name]; ok {
+			log.Fatalf("%s and %s are both named %s, give them different names with name=file", file, s.File, s.Name)
+		}
+		files[s.Name] = s.File
+		services = append(services, s)
+	}
+	for _, h := range topologyHosts {
+		name, host, ok := strings.Cut(h, "=")
+		if !ok || host == "" {
+			log.Fatalf("invalid host %s, expected name=host", h)
+		}
+		i := slices.IndexFunc(services, func(s *topology.Service) bool { return s.Name == name })
+		if i < 0 {
+			log.Fatalf("host %s is given for unknown service %s", host, name)
+		}
+		services[i].Hosts = append(services[i].Hosts, host)
+	}
+
+	if err := reporter.PrintTopology(topology.Build(services), topologyFormat, topologyOutput); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,340 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of changes that can be used to fail a diff, i.e. in CI
+const (
+	AddedRoutes    = "added-routes"
+	RemovedRoutes  = "removed-routes"
+	ChangedParams  = "changed-params"
+	NewPaths       = "new-paths"
+	RemovedPaths   = "removed-paths"
+	Recoverability = "recoverability"
+)
+
+var ChangeKinds = []string{AddedRoutes, RemovedRoutes, ChangedParams, NewPaths, RemovedPaths, Recoverability}
+
+// Match is a match as written by the json output format
+type Match struct {
+	MatchId     string
+	Indicator   indicator.Indicator
+	Params      map[string]string
+	Pos         string
+	EnclosedBy  string
+	Handler     string
+	PathLimited bool
+	Paths       [][]string
+	PathIds     []string
+}
+
+// Route identifies a match in a diff
+type Route struct {
+	MatchId    string
+	Indicator  string
+	Route      string
+	Pos        string
+	EnclosedBy string
+	Handler    string `json:",omitempty"`
+}
+
+type ParamChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+type RecoverabilityChange struct {
+	Path []string
+	Old  bool
+	New  bool
+}
+
+// RouteDiff holds the changes for a route found in both results
+type RouteDiff struct {
+	Route
+	OldMatchId            string                 `json:",omitempty"`
+	ParamChanges          []ParamChange          `json:",omitempty"`
+	NewPaths              [][]string             `json:",omitempty"`
+	RemovedPaths          [][]string             `json:",omitempty"`
+	RecoverabilityChanges []RecoverabilityChange `json:",omitempty"`
+}
+
+type Report struct {
+	Added     []Route
+	Removed   []Route
+	Changed   []RouteDiff
+	Unchanged int
+}
+
+// Load reads matches from a file written with the json output format
+func Load(path string) ([]Match, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	if err := json.Unmarshal(data, &matches); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	return matches, nil
+}
+
+// Compare pairs the matches of old and new results and reports the differences between them. Matches are paired
+// by match ID first. Matches whose ID changed, which happens when params change or the call moves, are then paired
+// by indicator, enclosing function and HTTP routes, and finally by indicator, enclosing function, file and line
+func Compare(oldMatches, newMatches []Match) *Report {
+	report := &Report{}
+	oldLeft := make(map[int]bool)
+	newLeft := make(map[int]bool)
+	for i := range oldMatches {
+		oldLeft[i] = true
+	}
+	for i := range newMatches {
+		newLeft[i] = true
+	}
+
+	keyFuncs := []func(m Match) string{
+		func(m Match) string { return m.MatchId },
+		func(m Match) string {
+			routes := routeKeys(m)
+			if routes == "" {
+				return ""
+			}
+			return strings.Join([]string{m.Indicator.Id, m.EnclosedBy, routes}, "\x00")
+		},
+		func(m Match) string {
+			file, line := fileAndLine(m.Pos)
+			return strings.Join([]string{m.Indicator.Id, m.EnclosedBy, file, line}, "\x00")
+		},
+	}
+
+	for _, keyFunc := range keyFuncs {
+		byKey := make(map[string][]int)
+		for _, i := range sortedIndexes(oldLeft) {
+			if k := keyFunc(oldMatches[i]); k != "" {
+				byKey[k] = append(byKey[k], i)
+			}
+		}
+		for _, j := range sortedIndexes(newLeft) {
+			k := keyFunc(newMatches[j])
+			if k == "" || len(byKey[k]) == 0 {
+				continue
+			}
+			i := byKey[k][0]
+			byKey[k] = byKey[k][1:]
+			delete(oldLeft, i)
+			delete(newLeft, j)
+
+			rd := compareMatches(oldMatches[i], newMatches[j])
+			if rd == nil {
+				report.Unchanged++
+			} else {
+				report.Changed = append(report.Changed, *rd)
+			}
+		}
+	}
+
+	for _, i := range sortedIndexes(oldLeft) {
+		report.Removed = append(report.Removed, newRoute(oldMatches[i]))
+	}
+	for _, j := range sortedIndexes(newLeft) {
+		report.Added = append(report.Added, newRoute(newMatches[j]))
+	}
+
+	return report
+}
+
+// Has reports whether the diff contains changes of the given kind
+func (r *Report) Has(kind string) bool {
+	switch kind {
+	case AddedRoutes:
+		return len(r.Added) > 0
+	case RemovedRoutes:
+		return len(r.Removed) > 0
+	}
+	for _, c := range r.Changed {
+		switch {
+		case kind == ChangedParams && len(c.ParamChanges) > 0,
+			kind == NewPaths && len(c.NewPaths) > 0,
+			kind == RemovedPaths && len(c.RemovedPaths) > 0,
+			kind == Recoverability && len(c.RecoverabilityChanges) > 0:
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether there are no differences between both results
+func (r *Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+func compareMatches(o, n Match) *RouteDiff {
+	rd := RouteDiff{Route: newRoute(n)}
+	if o.MatchId != n.MatchId {
+		rd.OldMatchId = o.MatchId
+	}
+
+	names := make(map[string]bool)
+	for k := range o.Params {
+		names[k] = true
+	}
+	for k := range n.Params {
+		names[k] = true
+	}
+	for _, k := range sortedStrings(names) {
+		ov, oOk := o.Params[k]
+		nv, nOk := n.Params[k]
+		if ov != nv || oOk != nOk {
+			rd.ParamChanges = append(rd.ParamChanges, ParamChange{Name: k, Old: ov, New: nv})
+		}
+	}
+
+	oldPaths := pathsByKey(o.Paths)
+	newPaths := pathsByKey(n.Paths)
+	for _, k := range sortedKeys(newPaths) {
+		np := newPaths[k]
+		op, ok := oldPaths[k]
+		if !ok {
+			rd.NewPaths = append(rd.NewPaths, np)
+			continue
+		}
+		if isRecoverable(op) != isRecoverable(np) {
+			rd.RecoverabilityChanges = append(rd.RecoverabilityChanges, RecoverabilityChange{
+				Path: np,
+				Old:  isRecoverable(op),
+				New:  isRecoverable(np),
+			})
+		}
+	}
+	for _, k := range sortedKeys(oldPaths) {
+		if _, ok := newPaths[k]; !ok {
+			rd.RemovedPaths = append(rd.RemovedPaths, oldPaths[k])
+		}
+	}
+
+	if len(rd.ParamChanges) == 0 && len(rd.NewPaths) == 0 && len(rd.RemovedPaths) == 0 && len(rd.RecoverabilityChanges) == 0 {
+		return nil
+	}
+	return &rd
+}
+
+func newRoute(m Match) Route {
+	return Route{
+		MatchId:    m.MatchId,
+		Indicator:  m.Indicator.Id,
+		Route:      RouteName(m),
+		Pos:        m.Pos,
+		EnclosedBy: m.EnclosedBy,
+		Handler:    m.Handler,
+	}
+}
+
+// RouteName describes a match by its HTTP routes, or by the indicator function and resolved params otherwise
+func RouteName(m Match) string {
+	if routes := routeKeys(m); routes != "" {
+		return routes
+	}
+	var params []string
+	for _, k := range sortedStrings(toSet(m.Params)) {
+		params = append(params, fmt.Sprintf("%s=%s", k, m.Params[k]))
+	}
+	return fmt.Sprintf("%s.%s(%s)", m.Indicator.Package, m.Indicator.Function, strings.Join(params, ", "))
+}
+
+func routeKeys(m Match) string {
+	rm := match.RouteMatch{Indicator: m.Indicator, Params: m.Params}
+	var routes []string
+	for _, r := range rm.HTTPRoutes() {
+		routes = append(routes, strings.TrimSpace(r.Method+" "+r.Path))
+	}
+	return strings.Join(routes, ", ")
+}
+
+var nodePosRe = regexp.MustCompile(`:\d+:\d+`)
+
+// pathKey identifies a path regardless of line numbers and recoverability, so that moving code around or
+// adding a recover does not show up as a new path
+func pathKey(path []string) string {
+	var nodes []string
+	for _, node := range path {
+		node = strings.Replace(node, " (recoverable)", "", 1)
+		nodes = append(nodes, nodePosRe.ReplaceAllString(node, ""))
+	}
+	return strings.Join(nodes, "\x00")
+}
+
+func pathsByKey(paths [][]string) map[string][]string {
+	res := make(map[string][]string)
+	for _, p := range paths {
+		res[pathKey(p)] = p
+	}
+	return res
+}
+
+func isRecoverable(path []string) bool {
+	for _, node := range path {
+		if strings.Contains(node, "(recoverable)") {
+			return true
+		}
+	}
+	return false
+}
+
+// fileAndLine splits a position formatted as file:line:column
+func fileAndLine(pos string) (string, string) {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 3 {
+		return pos, ""
+	}
+	if _, err := strconv.Atoi(parts[len(parts)-2]); err != nil {
+		return pos, ""
+	}
+	return filepath.Base(strings.Join(parts[:len(parts)-2], ":")), parts[len(parts)-2]
+}
+
+func sortedIndexes(set map[int]bool) []int {
+	var res []int
+	for i := range set {
+		res = append(res, i)
+	}
+	sort.Ints(res)
+	return res
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var res []string
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func sortedStrings(set map[string]bool) []string {
+	var res []string
+	for k := range set {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func toSet(m map[string]string) map[string]bool {
+	res := make(map[string]bool)
+	for k := range m {
+		res[k] = true
+	}
+	return res
+}
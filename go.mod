@@ -4,7 +4,6 @@ go 1.22.4
 
 require (
 	github.com/goccy/go-graphviz v0.1.2
-	github.com/google/uuid v1.6.0
 	github.com/spf13/cobra v1.8.0
 	golang.org/x/tools v0.22.0
 	gopkg.in/yaml.v2 v2.4.0
@@ -0,0 +1,393 @@
+package guess
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/wallylib"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+	"gopkg.in/yaml.v2"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of handlers recognized by their signature
+const (
+	// HTTPHandler is func(http.ResponseWriter, *http.Request)
+	HTTPHandler = "http"
+	// GinHandler is func(*gin.Context)
+	GinHandler = "gin"
+	// EchoHandler is func(echo.Context) error
+	EchoHandler = "echo"
+	// RPCHandler is a value passed as an interface whose methods look like gRPC methods, as in
+	// func(context.Context, *Req) (*Resp, error)
+	RPCHandler = "rpc"
+)
+
+var versionSuffix = regexp.MustCompile(`/v[0-9]+$`)
+
+// Registration is a call passing a handler to a registering function
+type Registration struct {
+	Handler string
+	Kind    string
+	Pos     token.Position
+}
+
+// Candidate is a function receiving handlers, which likely registers routes and is proposed as an indicator
+type Candidate struct {
+	Package      string
+	Function     string
+	ReceiverType string
+	// Params lists the string params of the function, which likely hold routes or methods
+	Params        []indicator.RouteParam
+	Registrations []Registration
+	// Known is set for functions already matched by the indicators passed to Guess
+	Known bool
+
+	fullName string
+}
+
+// Kinds returns the sorted kinds of the handlers registered with the candidate
+func (c *Candidate) Kinds() []string {
+	seen := map[string]bool{}
+	var kinds []string
+	for _, r := range c.Registrations {
+		if !seen[r.Kind] {
+			seen[r.Kind] = true
+			kinds = append(kinds, r.Kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Guess looks for functions whose signature looks like a handler in the syntax of pkgs, and returns the functions
+// receiving them as arguments, sorted by number of registrations. Functions matched by known are marked as Known.
+// Function handlers are only counted for functions also taking a string, such as a route, so that middleware and
+// servers wrapping a single handler are not proposed. http.Handler values also need a constant path among the args
+func Guess(pkgs []*packages.Package, known []indicator.Indicator) []*Candidate {
+	candidates := map[string]*Candidate{}
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		pass := &analysis.Pass{Fset: pkg.Fset, TypesInfo: pkg.TypesInfo, Pkg: pkg.Types}
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(node ast.Node) bool {
+				if ce, ok := node.(*ast.CallExpr); ok {
+					guessCall(ce, pass, known, candidates)
+				}
+				return true
+			})
+		}
+	}
+
+	var res []*Candidate
+	for _, c := range candidates {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if len(res[i].Registrations) != len(res[j].Registrations) {
+			return len(res[i].Registrations) > len(res[j].Registrations)
+		}
+		return res[i].fullName < res[j].fullName
+	})
+	return res
+}
+
+func guessCall(ce *ast.CallExpr, pass *analysis.Pass, known []indicator.Indicator, candidates map[string]*Candidate) {
+	info := pass.TypesInfo
+	if tv, ok := info.Types[ce.Fun]; ok && tv.IsType() {
+		return
+	}
+	fn, ok := typeutil.Callee(info, ce).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+	sig := fn.Type().(*types.Signature)
+	// Middleware returns the handler it wraps
+	for i := 0; i < sig.Results().Len(); i++ {
+		if t := sig.Results().At(i).Type(); handlerKind(t) != "" || implementsHandler(t) {
+			return
+		}
+	}
+	params := stringParams(sig)
+
+	// The last handler passed is registered, as in r.GET("/users", auth, listUsers)
+	for i := len(ce.Args) - 1; i >= 0; i-- {
+		arg := ce.Args[i]
+		kind := handlerKind(info.TypeOf(arg))
+		// Values implementing http.Handler, as in mux.Handle("/", auth(h)), are only counted along with a constant
+		// path, as servers such as http.ListenAndServe(":8080", mux) take one too
+		if kind == "" && implementsHandler(info.TypeOf(arg)) && hasPathArg(ce, info) {
+			kind = HTTPHandler
+		}
+		if kind != "" && len(params) == 0 {
+			continue
+		}
+		if kind == "" && isRPCService(paramType(sig, i), info.TypeOf(arg)) {
+			kind = RPCHandler
+		}
+		if kind == "" {
+			continue
+		}
+
+		c := candidates[fn.FullName()]
+		if c == nil {
+			c = &Candidate{
+				Package:      fn.Pkg().Path(),
+				Function:     fn.Name(),
+				ReceiverType: receiverType(sig),
+				Params:       params,
+				Known:        isKnown(ce, pass, known),
+				fullName:     fn.FullName(),
+			}
+			candidates[fn.FullName()] = c
+		}
+		handler := wallylib.DescribeHandlerExpr(arg, pass)
+		if kind == RPCHandler {
+			handler = types.TypeString(info.TypeOf(arg), nil)
+		}
+		c.Registrations = append(c.Registrations, Registration{
+			Handler: handler,
+			Kind:    kind,
+			Pos:     pass.Fset.Position(ce.Pos()),
+		})
+		return
+	}
+}
+
+// handlerKind returns the kind of handler of a function of type t, or an empty string if t does not look like a
+// handler
+func handlerKind(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok {
+		return ""
+	}
+	params, results := sig.Params(), sig.Results()
+	switch {
+	case params.Len() == 2 && results.Len() == 0 &&
+		isNamed(params.At(0).Type(), "net/http", "ResponseWriter", false) && isNamed(params.At(1).Type(), "net/http", "Request", true):
+		return HTTPHandler
+	case params.Len() == 1 && results.Len() == 0 && isNamed(params.At(0).Type(), "github.com/gin-gonic/gin", "Context", true):
+		return GinHandler
+	case params.Len() == 1 && results.Len() == 1 && isNamed(params.At(0).Type(), "github.com/labstack/echo", "Context", false) &&
+		isError(results.At(0).Type()):
+		return EchoHandler
+	}
+	return ""
+}
+
+// implementsHandler reports whether values of type t implement http.Handler
+func implementsHandler(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "ServeHTTP")
+	fn, ok := obj.(*types.Func)
+	return ok && handlerKind(fn.Type()) == HTTPHandler
+}
+
+// hasPathArg reports whether any argument of ce is a constant string holding a path, as in "/users" or "GET /users"
+func hasPathArg(ce *ast.CallExpr, info *types.Info) bool {
+	for _, arg := range ce.Args {
+		tv, ok := info.Types[arg]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			continue
+		}
+		if v := constant.StringVal(tv.Value); strings.Contains(v, "/") && !strings.Contains(v, "://") {
+			return true
+		}
+	}
+	return false
+}
+
+// isRPCService reports whether a value of type arg passed as param is an RPC service: param is an interface with
+// methods such as func(context.Context, *Req) (*Resp, error), which arg implements
+func isRPCService(param, arg types.Type) bool {
+	if param == nil || arg == nil {
+		return false
+	}
+	iface, ok := param.Underlying().(*types.Interface)
+	if !ok || types.IsInterface(arg) || !types.Implements(arg, iface) {
+		return false
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if isRPCMethod(iface.Method(i).Type().(*types.Signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRPCMethod(sig *types.Signature) bool {
+	params, results := sig.Params(), sig.Results()
+	if params.Len() != 2 || results.Len() != 2 {
+		return false
+	}
+	return isNamed(params.At(0).Type(), "context", "Context", false) && isStructPointer(params.At(1).Type()) &&
+		isStructPointer(results.At(0).Type()) && isError(results.At(1).Type())
+}
+
+// paramType returns the type of the parameter of sig receiving the argument i, including variadic arguments
+func paramType(sig *types.Signature, i int) types.Type {
+	params := sig.Params()
+	if params.Len() == 0 {
+		return nil
+	}
+	if sig.Variadic() && i >= params.Len()-1 {
+		if s, ok := params.At(params.Len() - 1).Type().(*types.Slice); ok {
+			return s.Elem()
+		}
+	}
+	if i >= params.Len() {
+		return nil
+	}
+	return params.At(i).Type()
+}
+
+// stringParams returns the string params of sig, by name when named
+func stringParams(sig *types.Signature) []indicator.RouteParam {
+	var params []indicator.RouteParam
+	for i := 0; i < sig.Params().Len(); i++ {
+		p := sig.Params().At(i)
+		if b, ok := p.Type().Underlying().(*types.Basic); !ok || b.Kind() != types.String {
+			continue
+		}
+		if p.Name() != "" && p.Name() != "_" {
+			params = append(params, indicator.RouteParam{Name: p.Name()})
+		} else {
+			params = append(params, indicator.RouteParam{Pos: i})
+		}
+	}
+	return params
+}
+
+func receiverType(sig *types.Signature) string {
+	if sig.Recv() == nil {
+		return ""
+	}
+	t := sig.Recv().Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+func isKnown(ce *ast.CallExpr, pass *analysis.Pass, known []indicator.Indicator) bool {
+	funcInfo, err := wallylib.GetFuncInfo(ce.Fun, pass.TypesInfo)
+	if err != nil {
+		return false
+	}
+	funcInfo.EnclosedBy = &wallylib.FuncDecl{Pkg: pass.Pkg}
+	return funcInfo.Match(known) != nil
+}
+
+// isNamed reports whether t is the named type name of pkgPath, ignoring major version suffixes, and whether it is a
+// pointer as expected
+func isNamed(t types.Type, pkgPath, name string, pointer bool) bool {
+	ptr, isPtr := t.(*types.Pointer)
+	if isPtr != pointer {
+		return false
+	}
+	if isPtr {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return versionSuffix.ReplaceAllString(named.Obj().Pkg().Path(), "") == pkgPath && named.Obj().Name() == name
+}
+
+func isStructPointer(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	_, ok = ptr.Elem().Underlying().(*types.Struct)
+	return ok
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+type proposal struct {
+	Indicators []proposedIndicator `yaml:"indicators"`
+}
+
+type proposedIndicator struct {
+	Id            string          `yaml:"id"`
+	Package       string          `yaml:"package"`
+	Function      string          `yaml:"function"`
+	ReceiverType  string          `yaml:"receiverType,omitempty"`
+	Params        []proposedParam `yaml:"params,omitempty"`
+	IndicatorType int             `yaml:"indicatorType"`
+	EntryKind     string          `yaml:"entryKind"`
+}
+
+type proposedParam struct {
+	Name string `yaml:"name,omitempty"`
+	Pos  *int   `yaml:"pos,omitempty"`
+}
+
+// Proposal returns a wally config proposing an indicator for each candidate, preceded by comments describing the
+// registrations the indicator is based on
+func Proposal(candidates []*Candidate) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("# Indicators proposed by wally guess. Review them, then run wally map -c with this file\n")
+
+	var p proposal
+	for i, c := range candidates {
+		id := fmt.Sprintf("guess-%d", i+1)
+		first := c.Registrations[0]
+		calls := fmt.Sprintf("%d calls", len(c.Registrations))
+		if len(c.Registrations) == 1 {
+			calls = "1 call"
+		}
+		fmt.Fprintf(&sb, "# %s: %s receives %s handlers in %s, as %s at %s:%d\n", id, c.fullName,
+			strings.Join(c.Kinds(), ", "), calls, first.Handler, filepath.Base(first.Pos.Filename), first.Pos.Line)
+
+		ind := proposedIndicator{
+			Id:            id,
+			Package:       c.Package,
+			Function:      c.Function,
+			ReceiverType:  c.ReceiverType,
+			IndicatorType: int(indicator.Service),
+			EntryKind:     indicator.HTTPEntry,
+		}
+		if kinds := c.Kinds(); len(kinds) == 1 && kinds[0] == RPCHandler {
+			ind.EntryKind = indicator.RPCEntry
+		}
+		for _, param := range c.Params {
+			if param.Name != "" {
+				ind.Params = append(ind.Params, proposedParam{Name: param.Name})
+				continue
+			}
+			pos := param.Pos
+			ind.Params = append(ind.Params, proposedParam{Pos: &pos})
+		}
+		p.Indicators = append(p.Indicators, ind)
+	}
+
+	out, err := yaml.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	sb.Write(out)
+	return []byte(sb.String()), nil
+}
@@ -7,6 +7,51 @@ type IndicatorType int
 const (
 	Service IndicatorType = iota
 	Caller
+	// EntryPoint indicators match code triggered from outside the application by something other than an HTTP or
+	// RPC request, such as a message consumed from a queue. The EntryKind of such indicators labels their matches
+	EntryPoint
+)
+
+// Kinds of entry points labeling matches
+const (
+	HTTPEntry      = "http"
+	RPCEntry       = "rpc"
+	QueueEntry     = "queue"
+	LambdaEntry    = "lambda"
+	GraphQLEntry   = "graphql"
+	WebSocketEntry = "websocket"
+	CommandEntry   = "command"
+	JobEntry       = "job"
+	WorkerEntry    = "worker"
+)
+
+// Kinds of outbound calls matched by Caller indicators
+const (
+	HTTPEgress  = "http"
+	GRPCEgress  = "grpc"
+	SQLEgress   = "sql"
+	RedisEgress = "redis"
+	KafkaEgress = "kafka"
+)
+
+// NoReceiver is the ReceiverType of indicators only matching functions, as in http.Get but not Header.Get
+const NoReceiver = "-"
+
+// RPC frameworks whose services are mapped per method
+const (
+	GRPCFramework    = "grpc"
+	ConnectFramework = "connect"
+	TwirpFramework   = "twirp"
+	GoKitFramework   = "go-kit"
+	// GQLGenFramework services are GraphQL schemas, mapped per resolver method
+	GQLGenFramework = "gqlgen"
+)
+
+// CLI frameworks, whose matches are commands arranged in a tree
+const (
+	CobraFramework     = "cobra"
+	UrfaveCLIFramework = "urfave-cli"
+	FlagFramework      = "flag"
 )
 
 type ParamType int
@@ -25,11 +70,75 @@ type Indicator struct {
 	IndicatorType IndicatorType `yaml:"indicatorType"`
 	ReceiverType  string        `yaml:"receiverType"`
 	MatchFilters  []string      `yaml:"matchFilter"`
+	// Framework is set for indicators of RPC and CLI frameworks. Matches of RPC indicators that register or construct
+	// a service are reported once per method of the service. Indicators with a Framework and a "*" package only match
+	// the service constructors generated for the framework, as in Register*Server for gRPC. Matches of CLI indicators
+	// are commands, arranged in a tree with the flags of each command
+	Framework string `yaml:"framework"`
+	// EntryKind labels the kind of entry point matched by the indicator, as in queue for message consumers
+	EntryKind string `yaml:"entryKind"`
+	// Struct is set for indicators matching composite literals of a struct type of Package rather than calls, as in
+	// cobra.Command. Params of such indicators name fields of the literal
+	Struct string `yaml:"struct"`
+	// HandlerFields names the fields of matched struct literals that may hold the handler, the first one set is used
+	HandlerFields []string `yaml:"handlerFields"`
+	// Egress labels the kind of outbound call matched by Caller indicators, as in http for HTTP clients. The first
+	// param of such indicators is the target of the call, such as a URL or an address
+	Egress string `yaml:"egress"`
+	// Go is set for indicators matching go statements in the functions of Package named Function, as in
+	// go worker.Run(ctx) in main, rather than calls to the function. The function started is the handler of the match
+	Go bool `yaml:"go"`
+	// SQL is set for Caller indicators whose first param is a SQL statement, as in the query passed to
+	// DB.QueryContext. Functions without the param, such as Stmt.Query, are not matched
+	SQL bool `yaml:"sql"`
+}
+
+// Kind returns the kind of entry point matched by the indicator, falling back to the name of its IndicatorType
+func (ind *Indicator) Kind() string {
+	if ind.EntryKind != "" {
+		return ind.EntryKind
+	}
+	switch ind.IndicatorType {
+	case Service:
+		return "service"
+	case Caller:
+		return "caller"
+	case EntryPoint:
+		return "entrypoint"
+	}
+	return ""
 }
 
 type RouteParam struct {
 	Name string `yaml:"name"`
 	Pos  int    `yaml:"pos"`
+	// Field is set for params passed in a struct, as in kafka.NewReader(kafka.ReaderConfig{Topic: "orders"}), and
+	// names the field of the struct literal holding the value
+	Field string `yaml:"field"`
+}
+
+// customIDPrefix names the custom indicators given without an ID, as in custom-1
+const customIDPrefix = "custom-"
+
+// ValidateIndicators returns an error if custom indicators reuse the ID of another custom indicator or, unless
+// skipDefault is set, of a stock indicator
+func ValidateIndicators(customIndicators []Indicator, skipDefault bool) error {
+	ids := make(map[string]string)
+	if !skipDefault {
+		for _, ind := range getStockIndicators() {
+			ids[ind.Id] = "stock indicator"
+		}
+	}
+	for _, ind := range customIndicators {
+		if ind.Id == "" {
+			continue
+		}
+		if other, ok := ids[ind.Id]; ok {
+			return fmt.Errorf("indicator %s.%s reuses ID %s of %s", ind.Package, ind.Function, ind.Id, other)
+		}
+		ids[ind.Id] = fmt.Sprintf("indicator %s.%s", ind.Package, ind.Function)
+	}
+	return nil
 }
 
 func InitIndicators(customIndicators []Indicator, skipDefault bool) []Indicator {
@@ -40,11 +149,11 @@ func InitIndicators(customIndicators []Indicator, skipDefault bool) []Indicator
 
 	if len(customIndicators) > 0 {
 		fmt.Println("Loading custom indicator")
-		idStart := len(indicators)
 		for i, ind := range customIndicators {
 			indCpy := ind
+			// Custom indicators are numbered on their own, so that their IDs do not change as stock indicators are added
 			if indCpy.Id == "" {
-				indCpy.Id = fmt.Sprintf("%d", idStart+i+1)
+				indCpy.Id = fmt.Sprintf("%s%d", customIDPrefix, i+1)
 			}
 			fmt.Println("Pkg: ", indCpy.Package)
 			fmt.Println("Func: ", indCpy.Function)
@@ -70,6 +179,7 @@ func getStockIndicators() []Indicator {
 			},
 			IndicatorType: Service,
 			MatchFilters:  []string{},
+			EntryKind:     HTTPEntry,
 		},
 		{
 			Id:       "2",
@@ -79,8 +189,757 @@ func getStockIndicators() []Indicator {
 			Params: []RouteParam{
 				{Name: "method"},
 			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        GRPCEgress,
+		},
+		{
+			Id:       "3",
+			Package:  "google.golang.org/grpc",
+			Type:     "",
+			Function: "RegisterService",
+			Params: []RouteParam{
+				{Name: "method"},
+			},
 			IndicatorType: Service,
 			MatchFilters:  []string{},
+			Framework:     GRPCFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:       "4",
+			Package:  "*",
+			Type:     "",
+			Function: "Register*Server",
+			Params: []RouteParam{
+				{Name: "method"},
+			},
+			IndicatorType: Service,
+			MatchFilters:  []string{},
+			Framework:     GRPCFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:       "5",
+			Package:  "connectrpc.com/connect",
+			Type:     "",
+			Function: "New*Handler",
+			Params: []RouteParam{
+				{Name: "procedure"},
+			},
+			IndicatorType: Service,
+			MatchFilters:  []string{},
+			Framework:     ConnectFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:       "6",
+			Package:  "*",
+			Type:     "",
+			Function: "New*Handler",
+			Params: []RouteParam{
+				{Name: "procedure"},
+			},
+			IndicatorType: Service,
+			MatchFilters:  []string{},
+			Framework:     ConnectFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:       "7",
+			Package:  "*",
+			Type:     "",
+			Function: "New*Server",
+			Params: []RouteParam{
+				{Name: "path"},
+			},
+			IndicatorType: Service,
+			MatchFilters:  []string{},
+			Framework:     TwirpFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:            "8",
+			Package:       "github.com/go-kit/kit/transport/http",
+			Type:          "",
+			Function:      "NewServer",
+			Params:        []RouteParam{},
+			IndicatorType: Service,
+			MatchFilters:  []string{},
+			Framework:     GoKitFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:            "9",
+			Package:       "github.com/go-kit/kit/transport/grpc",
+			Type:          "",
+			Function:      "NewServer",
+			Params:        []RouteParam{},
+			IndicatorType: Service,
+			MatchFilters:  []string{},
+			Framework:     GoKitFramework,
+			EntryKind:     RPCEntry,
+		},
+		{
+			Id:       "10",
+			Package:  "github.com/segmentio/kafka-go",
+			Type:     "",
+			Function: "NewReader",
+			Params: []RouteParam{
+				{Name: "config", Field: "Topic"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     QueueEntry,
+		},
+		{
+			Id:       "11",
+			Package:  "github.com/IBM/sarama",
+			Type:     "",
+			Function: "Consume",
+			Params: []RouteParam{
+				{Name: "topics"},
+			},
+			IndicatorType: EntryPoint,
+			ReceiverType:  "ConsumerGroup",
+			MatchFilters:  []string{},
+			EntryKind:     QueueEntry,
+		},
+		{
+			Id:       "12",
+			Package:  "github.com/confluentinc/confluent-kafka-go/v2/kafka",
+			Type:     "",
+			Function: "SubscribeTopics",
+			Params: []RouteParam{
+				{Name: "topics"},
+			},
+			IndicatorType: EntryPoint,
+			ReceiverType:  "Consumer",
+			MatchFilters:  []string{},
+			EntryKind:     QueueEntry,
+		},
+		{
+			Id:       "13",
+			Package:  "github.com/nats-io/nats.go",
+			Type:     "",
+			Function: "Subscribe",
+			Params: []RouteParam{
+				{Name: "subj"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     QueueEntry,
+		},
+		{
+			Id:       "14",
+			Package:  "github.com/nats-io/nats.go",
+			Type:     "",
+			Function: "QueueSubscribe",
+			Params: []RouteParam{
+				{Name: "subj"},
+				{Name: "queue"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     QueueEntry,
+		},
+		{
+			Id:       "15",
+			Package:  "github.com/aws/aws-sdk-go-v2/service/sqs",
+			Type:     "",
+			Function: "ReceiveMessage",
+			Params: []RouteParam{
+				{Name: "params", Field: "QueueUrl"},
+			},
+			IndicatorType: EntryPoint,
+			ReceiverType:  "Client",
+			MatchFilters:  []string{},
+			EntryKind:     QueueEntry,
+		},
+		{
+			Id:            "16",
+			Package:       "github.com/aws/aws-lambda-go/lambda",
+			Type:          "",
+			Function:      "Start*",
+			Params:        []RouteParam{},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     LambdaEntry,
+		},
+		{
+			Id:       "17",
+			Package:  "github.com/99designs/gqlgen/graphql/handler",
+			Type:     "",
+			Function: "New*",
+			Params: []RouteParam{
+				{Name: "field"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			Framework:     GQLGenFramework,
+			EntryKind:     GraphQLEntry,
+		},
+		{
+			Id:            "18",
+			Package:       "github.com/gorilla/websocket",
+			Type:          "",
+			Function:      "Upgrade",
+			Params:        []RouteParam{},
+			IndicatorType: EntryPoint,
+			ReceiverType:  "Upgrader",
+			MatchFilters:  []string{},
+			EntryKind:     WebSocketEntry,
+		},
+		{
+			Id:            "19",
+			Package:       "github.com/coder/websocket",
+			Type:          "",
+			Function:      "Accept",
+			Params:        []RouteParam{},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     WebSocketEntry,
+		},
+		{
+			Id:            "20",
+			Package:       "nhooyr.io/websocket",
+			Type:          "",
+			Function:      "Accept",
+			Params:        []RouteParam{},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     WebSocketEntry,
+		},
+		{
+			Id:      "21",
+			Package: "github.com/spf13/cobra",
+			Type:    "",
+			Struct:  "Command",
+			Params: []RouteParam{
+				{Field: "Use"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			Framework:     CobraFramework,
+			EntryKind:     CommandEntry,
+			HandlerFields: []string{"RunE", "Run"},
+		},
+		{
+			Id:      "22",
+			Package: "github.com/urfave/cli/v2",
+			Type:    "",
+			Struct:  "App",
+			Params: []RouteParam{
+				{Field: "Name"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			Framework:     UrfaveCLIFramework,
+			EntryKind:     CommandEntry,
+			HandlerFields: []string{"Action"},
+		},
+		{
+			Id:      "23",
+			Package: "github.com/urfave/cli/v2",
+			Type:    "",
+			Struct:  "Command",
+			Params: []RouteParam{
+				{Field: "Name"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			Framework:     UrfaveCLIFramework,
+			EntryKind:     CommandEntry,
+			HandlerFields: []string{"Action"},
+		},
+		{
+			Id:      "24",
+			Package: "github.com/urfave/cli/v3",
+			Type:    "",
+			Struct:  "Command",
+			Params: []RouteParam{
+				{Field: "Name"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			Framework:     UrfaveCLIFramework,
+			EntryKind:     CommandEntry,
+			HandlerFields: []string{"Action"},
+		},
+		{
+			Id:            "25",
+			Package:       "flag",
+			Type:          "",
+			Function:      "Parse",
+			Params:        []RouteParam{},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			Framework:     FlagFramework,
+			EntryKind:     CommandEntry,
+		},
+		{
+			Id:       "26",
+			Package:  "github.com/robfig/cron/v3",
+			Type:     "",
+			Function: "Add*",
+			Params: []RouteParam{
+				{Name: "spec"},
+			},
+			IndicatorType: EntryPoint,
+			ReceiverType:  "Cron",
+			MatchFilters:  []string{},
+			EntryKind:     JobEntry,
+		},
+		{
+			Id:       "27",
+			Package:  "github.com/robfig/cron",
+			Type:     "",
+			Function: "Add*",
+			Params: []RouteParam{
+				{Name: "spec"},
+			},
+			IndicatorType: EntryPoint,
+			ReceiverType:  "Cron",
+			MatchFilters:  []string{},
+			EntryKind:     JobEntry,
+		},
+		{
+			Id:       "28",
+			Package:  "time",
+			Type:     "",
+			Function: "NewTicker",
+			Params: []RouteParam{
+				{Name: "d"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     JobEntry,
+		},
+		{
+			Id:       "29",
+			Package:  "time",
+			Type:     "",
+			Function: "Tick",
+			Params: []RouteParam{
+				{Name: "d"},
+			},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     JobEntry,
+		},
+		{
+			Id:            "30",
+			Package:       "*",
+			Type:          "",
+			Function:      "main",
+			Params:        []RouteParam{},
+			IndicatorType: EntryPoint,
+			MatchFilters:  []string{},
+			EntryKind:     WorkerEntry,
+			Go:            true,
+		},
+		{
+			Id:       "31",
+			Package:  "net/http",
+			Type:     "",
+			Function: "NewRequest*",
+			Params: []RouteParam{
+				{Name: "url"},
+				{Name: "method"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "32",
+			Package:  "net/http",
+			Type:     "",
+			Function: "Get",
+			Params: []RouteParam{
+				{Name: "url"},
+			},
+			IndicatorType: Caller,
+			ReceiverType:  NoReceiver,
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "33",
+			Package:  "net/http",
+			Type:     "",
+			Function: "Head",
+			Params: []RouteParam{
+				{Name: "url"},
+			},
+			IndicatorType: Caller,
+			ReceiverType:  NoReceiver,
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "34",
+			Package:  "net/http",
+			Type:     "",
+			Function: "Post*",
+			Params: []RouteParam{
+				{Name: "url"},
+			},
+			IndicatorType: Caller,
+			ReceiverType:  NoReceiver,
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:            "35",
+			Package:       "net/http",
+			Type:          "",
+			Function:      "Do",
+			Params:        []RouteParam{},
+			IndicatorType: Caller,
+			ReceiverType:  "Client",
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "36",
+			Package:  "net/http",
+			Type:     "",
+			Function: "Get",
+			Params: []RouteParam{
+				{Name: "url"},
+			},
+			IndicatorType: Caller,
+			ReceiverType:  "Client",
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "37",
+			Package:  "net/http",
+			Type:     "",
+			Function: "Head",
+			Params: []RouteParam{
+				{Name: "url"},
+			},
+			IndicatorType: Caller,
+			ReceiverType:  "Client",
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "38",
+			Package:  "net/http",
+			Type:     "",
+			Function: "Post*",
+			Params: []RouteParam{
+				{Name: "url"},
+			},
+			IndicatorType: Caller,
+			ReceiverType:  "Client",
+			MatchFilters:  []string{},
+			Egress:        HTTPEgress,
+		},
+		{
+			Id:       "39",
+			Package:  "google.golang.org/grpc",
+			Type:     "",
+			Function: "Dial*",
+			Params: []RouteParam{
+				{Name: "target"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        GRPCEgress,
+		},
+		{
+			Id:       "40",
+			Package:  "google.golang.org/grpc",
+			Type:     "",
+			Function: "NewClient",
+			Params: []RouteParam{
+				{Name: "target"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        GRPCEgress,
+		},
+		{
+			Id:       "41",
+			Package:  "database/sql",
+			Type:     "",
+			Function: "Open",
+			Params: []RouteParam{
+				{Name: "driverName"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+		},
+		{
+			Id:       "42",
+			Package:  "github.com/redis/go-redis/v9",
+			Type:     "",
+			Function: "NewClient",
+			Params: []RouteParam{
+				{Name: "opt", Field: "Addr"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        RedisEgress,
+		},
+		{
+			Id:       "43",
+			Package:  "github.com/redis/go-redis/v9",
+			Type:     "",
+			Function: "NewClusterClient",
+			Params: []RouteParam{
+				{Name: "opt", Field: "Addrs"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        RedisEgress,
+		},
+		{
+			Id:       "44",
+			Package:  "github.com/go-redis/redis/v8",
+			Type:     "",
+			Function: "NewClient",
+			Params: []RouteParam{
+				{Name: "opt", Field: "Addr"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        RedisEgress,
+		},
+		{
+			Id:      "45",
+			Package: "github.com/segmentio/kafka-go",
+			Type:    "",
+			Struct:  "Writer",
+			Params: []RouteParam{
+				{Field: "Topic"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        KafkaEgress,
+		},
+		{
+			Id:       "46",
+			Package:  "github.com/IBM/sarama",
+			Type:     "",
+			Function: "New*Producer",
+			Params: []RouteParam{
+				{Name: "addrs"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        KafkaEgress,
+		},
+		{
+			Id:            "47",
+			Package:       "github.com/confluentinc/confluent-kafka-go/v2/kafka",
+			Type:          "",
+			Function:      "NewProducer",
+			Params:        []RouteParam{},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        KafkaEgress,
+		},
+		{
+			Id:       "48",
+			Package:  "database/sql",
+			Type:     "",
+			Function: "Query*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "49",
+			Package:  "database/sql",
+			Type:     "",
+			Function: "Exec*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "50",
+			Package:  "database/sql",
+			Type:     "",
+			Function: "Prepare*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "51",
+			Package:  "github.com/jmoiron/sqlx",
+			Type:     "",
+			Function: "Select*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "52",
+			Package:  "github.com/jmoiron/sqlx",
+			Type:     "",
+			Function: "Get*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "53",
+			Package:  "github.com/jmoiron/sqlx",
+			Type:     "",
+			Function: "Query*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "54",
+			Package:  "github.com/jmoiron/sqlx",
+			Type:     "",
+			Function: "Named*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "55",
+			Package:  "github.com/jmoiron/sqlx",
+			Type:     "",
+			Function: "MustExec*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "56",
+			Package:  "github.com/jmoiron/sqlx",
+			Type:     "",
+			Function: "Prepare*",
+			Params: []RouteParam{
+				{Name: "query"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "57",
+			Package:  "github.com/jackc/pgx/v5",
+			Type:     "",
+			Function: "Query*",
+			Params: []RouteParam{
+				{Name: "sql"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "58",
+			Package:  "github.com/jackc/pgx/v5",
+			Type:     "",
+			Function: "Exec",
+			Params: []RouteParam{
+				{Name: "sql"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "59",
+			Package:  "github.com/jackc/pgx/v5",
+			Type:     "",
+			Function: "Prepare",
+			Params: []RouteParam{
+				{Name: "sql"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "60",
+			Package:  "github.com/jackc/pgx/v5/pgxpool",
+			Type:     "",
+			Function: "Query*",
+			Params: []RouteParam{
+				{Name: "sql"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "61",
+			Package:  "github.com/jackc/pgx/v5/pgxpool",
+			Type:     "",
+			Function: "Exec",
+			Params: []RouteParam{
+				{Name: "sql"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
+		},
+		{
+			Id:       "62",
+			Package:  "github.com/jackc/pgx/v5/pgxpool",
+			Type:     "",
+			Function: "Prepare",
+			Params: []RouteParam{
+				{Name: "sql"},
+			},
+			IndicatorType: Caller,
+			MatchFilters:  []string{},
+			Egress:        SQLEgress,
+			SQL:           true,
 		},
 	}
 }
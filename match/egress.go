@@ -0,0 +1,45 @@
+package match
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Targets returns the resolved targets of an outbound call, from the first param of its indicator, as in the URL
+// passed to http.Get. Unresolved values are left out
+func (r *RouteMatch) Targets() []string {
+	if r.Indicator.Egress == "" || len(r.Indicator.Params) == 0 {
+		return nil
+	}
+	key := r.Indicator.Params[0].Name
+	if r.Indicator.Params[0].Field != "" {
+		key = r.Indicator.Params[0].Field
+	}
+	var targets []string
+	for _, v := range ParamValues(r.Params[key]) {
+		if !isUnresolved(v) {
+			targets = append(targets, v)
+		}
+	}
+	return targets
+}
+
+// TargetHost returns the host of the target of an outbound call: the host of URLs, or the target itself for
+// addresses such as localhost:6379. Returns an empty string for relative URLs
+func TargetHost(target string) string {
+	if strings.HasPrefix(target, "/") {
+		return ""
+	}
+	if !strings.Contains(target, "://") {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	if u.Host != "" {
+		return u.Host
+	}
+	// gRPC targets such as dns:///orders:443 have no authority
+	return strings.TrimPrefix(u.Path, "/")
+}
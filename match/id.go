@@ -0,0 +1,127 @@
+package match
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hex0punk/wally/wallynode"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// idVersion is part of every hashed ID, so that changes to the scheme below do not silently collide with older IDs
+const idVersion = "wally-id-v1"
+
+// idLength is the number of hex characters kept from the SHA-256 digest
+const idLength = 16
+
+// ContentID derives a match ID from the content of the match rather than from a random value, so that the same
+// finding keeps its ID across runs, machines and checkouts. The ID is the first 16 hex characters of the SHA-256
+// digest of the following fields, separated by NUL bytes:
+//
+//	wally-id-v1
+//	indicator ID
+//	position as <file relative to the module root, slash separated>:<line>:<column>
+//	enclosing function as <package path>.<function name>
+//	resolved params as <name>=<value>, sorted by name
+//
+// The ID changes when the call moves, the indicator changes, or the params resolve to different values
+func ContentID(indicatorID string, position string, enclosedBy string, params map[string]string) string {
+	fields := []string{idVersion, indicatorID, position, enclosedBy}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, k+"="+params[k])
+	}
+
+	return hashFields(fields)
+}
+
+// ModuleRelativePosition formats the position of a match with the filename relative to moduleDir. If the module
+// directory is unknown or the file is outside of it, only the base name of the file is used
+func (r *RouteMatch) ModuleRelativePosition(moduleDir string) string {
+	return moduleRelativePosition(r.Pos, moduleDir)
+}
+
+func moduleRelativePosition(pos token.Position, moduleDir string) string {
+	filename := filepath.Base(pos.Filename)
+	if moduleDir != "" {
+		if rel, err := filepath.Rel(moduleDir, pos.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d:%d", filepath.ToSlash(filename), pos.Line, pos.Column)
+}
+
+// pathID derives the ID of a call path from the ID of its match and the keys of the nodes of the path, from the root
+// of the path to the match, using the same hashing scheme as ContentID
+func pathID(matchID string, keys []string) string {
+	return hashFields(append([]string{idVersion, matchID}, keys...))
+}
+
+// nodeKey identifies a node of a call path by its function and the position of its call site, or of the function for
+// nodes without a site, relative to the root of the module of the function. Unlike node strings, keys do not depend
+// on the working directory
+func nodeKey(node wallynode.WallyNode, moduleDir func(*types.Package) string) string {
+	fn := node.Caller.Func
+	pos := fn.Pos()
+	if node.Site != nil {
+		pos = node.Site.Pos()
+	}
+	dir := ""
+	if fn.Pkg != nil && moduleDir != nil {
+		dir = moduleDir(fn.Pkg.Pkg)
+	}
+	return fn.String() + " " + moduleRelativePosition(fn.Prog.Fset.Position(pos), dir)
+}
+
+func hashFields(fields []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// SortPaths orders paths by their nodes, from the root of each path to the match, and sets the ID of every path.
+// moduleDir returns the root directory of the module of a package, used to make node positions module relative
+func (cp *CallPaths) SortPaths(matchID string, moduleDir func(*types.Package) string) {
+	keys := make(map[*CallPath]string, len(cp.Paths))
+	for _, path := range cp.Paths {
+		var nodes []string
+		for x := len(path.Nodes) - 1; x >= 0; x-- {
+			nodes = append(nodes, nodeKey(path.Nodes[x], moduleDir))
+		}
+		keys[path] = strings.Join(nodes, "\x00")
+		path.ID = pathID(matchID, nodes)
+	}
+
+	sort.SliceStable(cp.Paths, func(i, j int) bool {
+		return keys[cp.Paths[i]] < keys[cp.Paths[j]]
+	})
+}
+
+// SortMatches orders matches by file, line, column and indicator ID, so that output does not depend on the order
+// in which packages were analyzed
+func SortMatches(matches []RouteMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Pos.Filename != b.Pos.Filename {
+			return a.Pos.Filename < b.Pos.Filename
+		}
+		if a.Pos.Line != b.Pos.Line {
+			return a.Pos.Line < b.Pos.Line
+		}
+		if a.Pos.Column != b.Pos.Column {
+			return a.Pos.Column < b.Pos.Column
+		}
+		if a.Indicator.Id != b.Indicator.Id {
+			return a.Indicator.Id < b.Indicator.Id
+		}
+		return a.MatchId < b.MatchId
+	})
+}
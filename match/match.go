@@ -3,8 +3,8 @@ package match
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/google/uuid"
 	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/wallylib"
 	"github.com/hex0punk/wally/wallynode"
 	"go/token"
 	"go/types"
@@ -19,9 +19,41 @@ type RouteMatch struct {
 	Signature  *types.Signature
 	EnclosedBy string
 	Module     string
+	Handler    string
+	// Auth holds the configured auth middleware found wrapping the route, in the order they run. It is nil if
+	// no auth middleware was configured, and empty if none was found
+	Auth []string
+	// Middleware is the chain of functions wrapping the handler of the route, from the outermost to the innermost.
+	// It is resolved from SSA, and is nil when SSA is not used
+	Middleware []wallylib.Middleware
+	// Inputs lists the request inputs read by the handler of the route and the functions it calls. It is nil if
+	// the handler could not be found
+	Inputs []wallylib.Input
+	// Responses describes what the handler of the route writes back. It is nil if the handler could not be found
+	Responses *wallylib.Responses
+	// Command is the path of the CLI command of the match, from the root command, as in wally map search. It is only
+	// set for commands
+	Command string
+	// Parent is the ID of the match of the parent command, if it is reported
+	Parent string
+	// Flags lists the flags defined or read for the command. It is nil for matches that are not commands
+	Flags []string
+	// ReachedFrom lists the IDs of the matches whose handlers are in the call paths of an outbound call. It is nil for
+	// matches that are not outbound calls, or when call paths were not solved
+	ReachedFrom []string
+	// Suppression is set for matches accepted via an inline comment or a baseline file
+	Suppression *Suppression
+	// Violations holds the policy rules the match does not meet
+	Violations []PolicyViolation
 	SSA        *SSAContext
 }
 
+type PolicyViolation struct {
+	PolicyId    string
+	Description string `json:",omitempty"`
+	Message     string
+}
+
 // TODO: I don't love this here, maybe an SSA dedicated pkg would be better
 type SSAContext struct {
 	PathLimited    bool
@@ -29,7 +61,9 @@ type SSAContext struct {
 	CallPaths      *CallPaths
 	SSAInstruction ssa.CallInstruction
 	SSAFunc        *ssa.Function
-	TargetPos      string
+	// HandlerFunc is the function run by the handler of the match, used to find the matches reaching outbound calls
+	HandlerFunc *ssa.Function
+	TargetPos   string
 }
 
 type CallPaths struct {
@@ -37,7 +71,7 @@ type CallPaths struct {
 }
 
 type CallPath struct {
-	ID            int
+	ID            string
 	Nodes         []wallynode.WallyNode
 	NodeLimited   bool
 	FilterLimited bool
@@ -94,9 +128,10 @@ func (cp *CallPaths) Print() {
 	}
 }
 
+// NewRouteMatch creates a match for a call to a function of the indicator at pos. The MatchId should be set with
+// ContentID once the params and enclosing function of the match are known
 func NewRouteMatch(indicator indicator.Indicator, pos token.Position) RouteMatch {
 	return RouteMatch{
-		MatchId:   uuid.New().String(),
 		Indicator: indicator,
 		Pos:       pos,
 		SSA:       &SSAContext{},
@@ -122,30 +157,93 @@ func (r *RouteMatch) MarshalJSON() ([]byte, error) {
 	}
 
 	var resPaths [][]string
-	for _, paths := range r.SSA.CallPaths.Paths {
-		var p []string
-		for x := len(paths.Nodes) - 1; x >= 0; x-- {
-			p = append(p, paths.Nodes[x].NodeString)
+	var pathIds []string
+	var pathLimited bool
+	if r.SSA != nil {
+		pathLimited = r.SSA.PathLimited
+		if r.SSA.CallPaths != nil {
+			for _, paths := range r.SSA.CallPaths.Paths {
+				var p []string
+				for x := len(paths.Nodes) - 1; x >= 0; x-- {
+					p = append(p, paths.Nodes[x].NodeString)
+				}
+				p = append(p, r.SSA.TargetPos)
+				resPaths = append(resPaths, p)
+				pathIds = append(pathIds, paths.ID)
+			}
 		}
-		p = append(p, r.SSA.TargetPos)
-		resPaths = append(resPaths, p)
+	}
+
+	// Auth is omitted when it was not analyzed, and an empty list when no auth middleware was found
+	var auth *[]string
+	if r.Auth != nil {
+		auth = &r.Auth
+	}
+
+	// Same for the middleware chain, which is only resolved with SSA
+	var middleware *[]wallylib.Middleware
+	if r.Middleware != nil {
+		middleware = &r.Middleware
+	}
+
+	var inputs *[]wallylib.Input
+	if r.Inputs != nil {
+		inputs = &r.Inputs
+	}
+
+	var flags *[]string
+	if r.Flags != nil {
+		flags = &r.Flags
+	}
+
+	var reachedFrom *[]string
+	if r.ReachedFrom != nil {
+		reachedFrom = &r.ReachedFrom
 	}
 
 	return json.Marshal(struct {
 		MatchId     string
 		Indicator   indicator.Indicator
+		Kind        string
 		Params      map[string]string
 		Pos         string
 		EnclosedBy  string
+		Handler     string                 `json:",omitempty"`
+		Auth        *[]string              `json:",omitempty"`
+		Middleware  *[]wallylib.Middleware `json:",omitempty"`
+		Inputs      *[]wallylib.Input      `json:",omitempty"`
+		Responses   *wallylib.Responses    `json:",omitempty"`
+		Command     string                 `json:",omitempty"`
+		Parent      string                 `json:",omitempty"`
+		Flags       *[]string              `json:",omitempty"`
+		ReachedFrom *[]string              `json:",omitempty"`
+		Module      string                 `json:",omitempty"`
+		Suppression *Suppression           `json:",omitempty"`
+		Violations  []PolicyViolation      `json:",omitempty"`
 		PathLimited bool
 		Paths       [][]string
+		PathIds     []string `json:",omitempty"`
 	}{
 		MatchId:     r.MatchId,
 		Indicator:   r.Indicator,
+		Kind:        r.Indicator.Kind(),
 		Params:      params,
 		Pos:         r.Pos.String(),
 		EnclosedBy:  enclosedBy,
-		PathLimited: r.SSA.PathLimited,
+		Handler:     r.Handler,
+		Auth:        auth,
+		Middleware:  middleware,
+		Inputs:      inputs,
+		Responses:   r.Responses,
+		Command:     r.Command,
+		Parent:      r.Parent,
+		Flags:       flags,
+		ReachedFrom: reachedFrom,
+		Module:      r.Module,
+		Suppression: r.Suppression,
+		Violations:  r.Violations,
+		PathLimited: pathLimited,
 		Paths:       resPaths,
+		PathIds:     pathIds,
 	})
 }
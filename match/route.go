@@ -0,0 +1,190 @@
+package match
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HTTPRoute is a method and path pair derived from the resolved params of a match
+type HTTPRoute struct {
+	Method string
+	Path   string
+}
+
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"CONNECT": true,
+	"OPTIONS": true,
+	"TRACE":   true,
+}
+
+// Param names commonly used for paths by routers. Values starting with "/" are treated as paths as well
+var pathParamNames = map[string]bool{
+	"pattern":      true,
+	"path":         true,
+	"relativePath": true,
+	"route":        true,
+	"tpl":          true,
+	"endpoint":     true,
+}
+
+var methodParamNames = map[string]bool{
+	"method":     true,
+	"methods":    true,
+	"httpMethod": true,
+}
+
+// HTTPRoutes returns the routes that could be derived from the resolved params of the match.
+// A param resolved to multiple values (i.e. "/a || /b") results in multiple routes. Method is
+// empty if it could not be determined. Returns nil if no path was resolved for the match
+func (r *RouteMatch) HTTPRoutes() []HTTPRoute {
+	var methods, paths []string
+	for _, k := range sortedKeys(r.Params) {
+		for _, v := range ParamValues(r.Params[k]) {
+			if isUnresolved(v) {
+				continue
+			}
+			upper := strings.ToUpper(v)
+			if httpMethods[upper] && (methodParamNames[k] || !pathParamNames[k]) {
+				methods = append(methods, upper)
+				continue
+			}
+			if pathParamNames[k] || strings.HasPrefix(v, "/") || strings.Contains(v, " /") {
+				paths = append(paths, v)
+			}
+		}
+	}
+
+	var routes []HTTPRoute
+	for _, p := range paths {
+		method, path := splitPattern(p)
+		if method != "" {
+			routes = append(routes, HTTPRoute{Method: method, Path: path})
+			continue
+		}
+		if len(methods) == 0 {
+			routes = append(routes, HTTPRoute{Path: path})
+			continue
+		}
+		for _, m := range methods {
+			routes = append(routes, HTTPRoute{Method: m, Path: path})
+		}
+	}
+	return routes
+}
+
+// ParamValues splits a resolved param into its possible values, removing quotes from string literals.
+// Values recorded for local variables are joined with " || " and composite literals are separated by spaces
+func ParamValues(param string) []string {
+	var vals []string
+	for _, v := range splitValues(param) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if uq, ok := unquoteConcat(v); ok {
+			vals = append(vals, uq)
+			continue
+		}
+		// Composite literals, i.e. []string{"GET", "POST"}, are resolved to space separated literals
+		fields := strings.Fields(v)
+		if len(fields) > 1 && allQuoted(fields) {
+			for _, f := range fields {
+				uq, _ := unquoteConcat(f)
+				vals = append(vals, uq)
+			}
+			continue
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+// splitPattern splits patterns in the form "[METHOD ][HOST]/[PATH]" as supported by net/http since Go 1.22
+func splitPattern(pattern string) (string, string) {
+	method := ""
+	if before, after, found := strings.Cut(pattern, " "); found && httpMethods[before] {
+		method = before
+		pattern = strings.TrimSpace(after)
+	}
+	if idx := strings.Index(pattern, "/"); idx > 0 && isHost(pattern[:idx]) {
+		pattern = pattern[idx:]
+	}
+	return method, pattern
+}
+
+// isHost reports whether the part of a pattern before the first slash is a host, as in example.com/users, rather
+// than the first segment of a relative path, as in users/:id for router groups
+func isHost(s string) bool {
+	return s == "localhost" || strings.ContainsAny(s, ".:")
+}
+
+func isUnresolved(v string) bool {
+	return v == "" || strings.Contains(v, "<var ") || strings.Contains(v, "<BinExp") || strings.Contains(v, "<could not resolve>")
+}
+
+// unquoteConcat unquotes a string literal, or the concatenation of string literals
+// such as `"GET ""/users"` which is how binary expressions of literals are resolved
+func unquoteConcat(v string) (string, bool) {
+	var sb strings.Builder
+	for rest := v; rest != ""; {
+		lit, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return "", false
+		}
+		uq, err := strconv.Unquote(lit)
+		if err != nil {
+			return "", false
+		}
+		sb.WriteString(uq)
+		rest = rest[len(lit):]
+	}
+	return sb.String(), true
+}
+
+// splitValues splits a resolved param on the " || " joining the values recorded for local variables. Separators
+// within string literals, such as the SQL concatenation operator, are kept
+func splitValues(param string) []string {
+	var vals []string
+	start := 0
+	for i := 0; i < len(param); {
+		switch {
+		case param[i] == '"' || param[i] == '`':
+			if lit, err := strconv.QuotedPrefix(param[i:]); err == nil {
+				i += len(lit)
+				continue
+			}
+		case strings.HasPrefix(param[i:], " || "):
+			vals = append(vals, param[start:i])
+			i += len(" || ")
+			start = i
+			continue
+		}
+		i++
+	}
+	return append(vals, param[start:])
+}
+
+func allQuoted(fields []string) bool {
+	for _, f := range fields {
+		if _, ok := unquoteConcat(f); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
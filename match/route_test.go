@@ -0,0 +1,88 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		method  string
+		path    string
+	}{
+		{"/users", "", "/users"},
+		{"GET /users/{id}", "GET", "/users/{id}"},
+		{"example.com/users", "", "/users"},
+		{"POST api.example.com/orders", "POST", "/orders"},
+		{"localhost/health", "", "/health"},
+		{"localhost:8080/health", "", "/health"},
+		{"users/:id", "", "users/:id"},
+		{"api/v1/x", "", "api/v1/x"},
+		{"GET users/:id", "GET", "users/:id"},
+		{"users", "", "users"},
+	}
+	for _, tt := range tests {
+		method, path := splitPattern(tt.pattern)
+		if method != tt.method || path != tt.path {
+			t.Errorf("splitPattern(%q) = %q, %q, want %q, %q", tt.pattern, method, path, tt.method, tt.path)
+		}
+	}
+}
+
+func TestHTTPRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []HTTPRoute
+	}{
+		{
+			name:   "method in pattern",
+			params: map[string]string{"pattern": `"GET /users/{id}"`},
+			want:   []HTTPRoute{{Method: "GET", Path: "/users/{id}"}},
+		},
+		{
+			name:   "host in pattern",
+			params: map[string]string{"pattern": `"example.com/users"`},
+			want:   []HTTPRoute{{Path: "/users"}},
+		},
+		{
+			name:   "relative group path",
+			params: map[string]string{"relativePath": `"users/:id"`},
+			want:   []HTTPRoute{{Path: "users/:id"}},
+		},
+		{
+			name:   "method param",
+			params: map[string]string{"method": `"POST"`, "path": `"/orders"`},
+			want:   []HTTPRoute{{Method: "POST", Path: "/orders"}},
+		},
+		{
+			name:   "multiple values",
+			params: map[string]string{"path": `"/a" ||  "/b"`},
+			want:   []HTTPRoute{{Path: "/a"}, {Path: "/b"}},
+		},
+		{
+			name:   "separator in literal",
+			params: map[string]string{"path": `"/a || b"`},
+			want:   []HTTPRoute{{Path: "/a || b"}},
+		},
+		{
+			name:   "concatenation",
+			params: map[string]string{"path": `"/api""/users"`},
+			want:   []HTTPRoute{{Path: "/api/users"}},
+		},
+		{
+			name:   "unresolved",
+			params: map[string]string{"path": `<var p.p>`},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RouteMatch{Params: tt.params}
+			if got := r.HTTPRoutes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HTTPRoutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package match
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Statement is a SQL statement passed to a database client, as resolved from the params of a match
+type Statement struct {
+	// Query is the resolved statement, with non-constant parts shown as resolved, as in <var id.id>
+	Query string
+	// Verb is the kind of statement, as in SELECT or INSERT, or empty when the statement could not be resolved
+	Verb   string
+	Tables []string
+	// Dynamic is set for statements built with non-constant parts, such as a variable concatenated to the query,
+	// which are potential injection points
+	Dynamic bool
+}
+
+// sqlKeywords are words not taken as table names where a table is expected, as in DELETE FROM or DROP TABLE IF EXISTS
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "SET": true, "VALUES": true, "ONLY": true, "IF": true, "NOT": true,
+	"EXISTS": true, "LATERAL": true, "AS": true, "ON": true, "USING": true, "JOIN": true, "IGNORE": true,
+}
+
+// sqlVerbs are the verbs of the main statement of WITH queries
+var sqlVerbs = map[string]bool{"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true}
+
+// Statements returns the statements passed to the SQL client matched by r, from the first param of its indicator.
+// A statement that could not be resolved at all, such as one returned by a function, is returned as an empty
+// dynamic statement
+func (r *RouteMatch) Statements() []Statement {
+	if !r.Indicator.SQL || len(r.Indicator.Params) == 0 {
+		return nil
+	}
+	// Values are split but not unquoted as done by ParamValues, which would lose the non-constant parts
+	var statements []Statement
+	for _, v := range splitValues(r.Params[r.Indicator.Params[0].Name]) {
+		if v = strings.TrimSpace(v); v != "" {
+			statements = append(statements, ParseStatement(v))
+		}
+	}
+	if len(statements) == 0 {
+		return []Statement{{Dynamic: true}}
+	}
+	return statements
+}
+
+// ParseStatement parses a statement as resolved for a param: a string literal, or a concatenation of string literals
+// and non-constant parts, as in "SELECT * FROM users WHERE id = "<var id.id>
+func ParseStatement(val string) Statement {
+	var query, tokens strings.Builder
+	st := Statement{}
+	for rest := val; rest != ""; {
+		if rest[0] == '"' || rest[0] == '`' {
+			if lit, err := strconv.QuotedPrefix(rest); err == nil {
+				uq, _ := strconv.Unquote(lit)
+				query.WriteString(uq)
+				tokens.WriteString(uq)
+				rest = rest[len(lit):]
+				continue
+			}
+		}
+		// A non-constant part runs until the next string literal
+		end := strings.IndexAny(rest[1:], "\"`") + 1
+		if end == 0 {
+			end = len(rest)
+		}
+		query.WriteString(strings.TrimSpace(rest[:end]))
+		// Non-constant parts are read as a placeholder, so that they are not taken for tables
+		tokens.WriteString("?")
+		st.Dynamic = true
+		rest = rest[end:]
+	}
+	st.Query = query.String()
+	st.Verb, st.Tables = ClassifySQL(tokens.String())
+	return st
+}
+
+// ClassifySQL returns the verb of a SQL statement, as in SELECT, and the tables it reads or writes, in order of
+// appearance. Tables are those following FROM, JOIN, INTO, UPDATE and TABLE, leaving out names defined by WITH
+func ClassifySQL(query string) (string, []string) {
+	tokens := tokenizeSQL(query)
+	if len(tokens) == 0 || !tokens[0].word {
+		return "", nil
+	}
+
+	verb := strings.ToUpper(tokens[0].text)
+	ctes := map[string]bool{}
+	if verb == "WITH" {
+		verb = ""
+		depth := 0
+		for i, t := range tokens {
+			switch {
+			case t.text == "(":
+				depth++
+			case t.text == ")":
+				depth--
+			case depth == 0 && isCTEStart(tokens, i):
+				ctes[strings.ToLower(tokens[i-1].text)] = true
+			case depth == 0 && t.word && sqlVerbs[strings.ToUpper(t.text)] && verb == "":
+				verb = strings.ToUpper(t.text)
+			}
+		}
+	}
+
+	var tables []string
+	addTable := func(i int) int {
+		for i < len(tokens) && tokens[i].word && sqlKeywords[strings.ToUpper(tokens[i].text)] && !tokens[i].quoted {
+			i++
+		}
+		if i >= len(tokens) || !tokens[i].word {
+			return i
+		}
+		// Names built with non-constant parts are left out
+		name := tokens[i].text
+		if !ctes[strings.ToLower(name)] && !strings.Contains(name, "?") && !slices.Contains(tables, name) {
+			tables = append(tables, name)
+		}
+		return i + 1
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if !t.word || t.quoted {
+			continue
+		}
+		switch strings.ToUpper(t.text) {
+		case "FROM":
+			i = addTable(i + 1)
+			// Lists of tables, as in FROM users u, orders AS o
+			for {
+				if i < len(tokens) && strings.EqualFold(tokens[i].text, "AS") {
+					i++
+				}
+				if i < len(tokens) && tokens[i].word && !isClause(tokens[i].text) {
+					i++
+				}
+				if i >= len(tokens) || tokens[i].text != "," {
+					break
+				}
+				i = addTable(i + 1)
+			}
+			i--
+		case "JOIN", "INTO", "TABLE":
+			i = addTable(i+1) - 1
+		case "UPDATE":
+			// UPDATE as a verb, not as in ON CONFLICT DO UPDATE or FOR UPDATE
+			if i == 0 || (verb == "UPDATE" && !isUpdateClause(tokens, i)) {
+				i = addTable(i+1) - 1
+			}
+		}
+	}
+	return verb, tables
+}
+
+// isCTEStart reports whether the AS at i defines a name of a WITH query, as in recent AS (SELECT ...)
+func isCTEStart(tokens []sqlToken, i int) bool {
+	return i > 0 && i+1 < len(tokens) && strings.EqualFold(tokens[i].text, "AS") && tokens[i+1].text == "("
+}
+
+// isUpdateClause reports whether the UPDATE at i is part of a clause, as in DO UPDATE, rather than a statement
+func isUpdateClause(tokens []sqlToken, i int) bool {
+	if i == 0 {
+		return false
+	}
+	prev := strings.ToUpper(tokens[i-1].text)
+	return prev == "DO" || prev == "FOR" || prev == "KEY"
+}
+
+// isClause reports whether word starts a clause following a list of tables, as in WHERE
+func isClause(word string) bool {
+	switch strings.ToUpper(word) {
+	case "WHERE", "GROUP", "ORDER", "LIMIT", "OFFSET", "HAVING", "UNION", "INNER", "LEFT", "RIGHT", "FULL", "CROSS",
+		"NATURAL", "JOIN", "ON", "RETURNING", "FOR", "WINDOW", "EXCEPT", "INTERSECT", "SET", "VALUES":
+		return true
+	}
+	return false
+}
+
+type sqlToken struct {
+	text string
+	// word is set for identifiers and keywords, including quoted identifiers and names qualified by a schema
+	word   bool
+	quoted bool
+}
+
+// tokenizeSQL splits a SQL statement into words and punctuation, skipping comments and string literals
+func tokenizeSQL(query string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'':
+			// String literals, with '' escaping a quote
+			j := i + 1
+			for j < len(query) {
+				if query[j] == '\'' {
+					if j+1 < len(query) && query[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: "''"})
+			i = j + 1
+		case c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return tokens
+			}
+			tokens = appendWord(tokens, query[i+1:i+1+end], true)
+			i += end + 2
+		case isWordByte(c) || c == '?' && i+1 < len(query) && isWordByte(query[i+1]):
+			// Non-constant parts read as ? are kept in the words they are part of, as in audit_?
+			j := i
+			for j < len(query) && (isWordByte(query[j]) || query[j] == '?') {
+				j++
+			}
+			tokens = appendWord(tokens, query[i:j], false)
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// appendWord appends a word to tokens, joining it to the previous word when qualified, as in public.users
+func appendWord(tokens []sqlToken, word string, quoted bool) []sqlToken {
+	if n := len(tokens); n >= 2 && tokens[n-1].text == "." && tokens[n-2].word {
+		tokens[n-2].text += "." + word
+		return tokens[:n-1]
+	}
+	return append(tokens, sqlToken{text: word, word: true, quoted: quoted})
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,188 @@
+package match
+
+import (
+	"github.com/hex0punk/wally/indicator"
+	"reflect"
+	"testing"
+)
+
+func TestClassifySQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		verb   string
+		tables []string
+	}{
+		{
+			name:   "select",
+			query:  "SELECT id, name FROM users WHERE id = $1",
+			verb:   "SELECT",
+			tables: []string{"users"},
+		},
+		{
+			name:   "join",
+			query:  "SELECT o.id FROM orders o JOIN users u ON u.id = o.user_id",
+			verb:   "SELECT",
+			tables: []string{"orders", "users"},
+		},
+		{
+			name:   "list of tables",
+			query:  "SELECT * FROM a, b AS c WHERE a.id = c.id",
+			verb:   "SELECT",
+			tables: []string{"a", "b"},
+		},
+		{
+			name:   "cte",
+			query:  "WITH recent AS (SELECT * FROM orders WHERE created_at > now()) SELECT * FROM recent JOIN users ON users.id = recent.user_id",
+			verb:   "SELECT",
+			tables: []string{"orders", "users"},
+		},
+		{
+			name:   "cte insert",
+			query:  "WITH moved AS (DELETE FROM queue RETURNING *) INSERT INTO archive SELECT * FROM moved",
+			verb:   "INSERT",
+			tables: []string{"queue", "archive"},
+		},
+		{
+			name:   "on conflict do update",
+			query:  "INSERT INTO counters (id, n) VALUES ($1, 1) ON CONFLICT (id) DO UPDATE SET n = counters.n + 1",
+			verb:   "INSERT",
+			tables: []string{"counters"},
+		},
+		{
+			name:   "for update",
+			query:  "SELECT * FROM jobs WHERE state = 'new' FOR UPDATE SKIP LOCKED",
+			verb:   "SELECT",
+			tables: []string{"jobs"},
+		},
+		{
+			name:   "update",
+			query:  "UPDATE accounts SET name = ? WHERE id = 1",
+			verb:   "UPDATE",
+			tables: []string{"accounts"},
+		},
+		{
+			name:   "quoted identifiers",
+			query:  `SELECT * FROM "Users" JOIN ` + "`order items`" + ` ON 1 = 1`,
+			verb:   "SELECT",
+			tables: []string{"Users", "order items"},
+		},
+		{
+			name:   "quoted keyword as table",
+			query:  `DELETE FROM "select" WHERE id = 1`,
+			verb:   "DELETE",
+			tables: []string{"select"},
+		},
+		{
+			name:   "schema",
+			query:  "DROP TABLE IF EXISTS public.sessions",
+			verb:   "DROP",
+			tables: []string{"public.sessions"},
+		},
+		{
+			name:   "comments",
+			query:  "-- FROM ignored\nSELECT /* JOIN hidden */ * FROM visible",
+			verb:   "SELECT",
+			tables: []string{"visible"},
+		},
+		{
+			name:   "string literals",
+			query:  "SELECT * FROM notes WHERE body = 'it''s FROM here'",
+			verb:   "SELECT",
+			tables: []string{"notes"},
+		},
+		{
+			name:  "dynamic table",
+			query: "INSERT INTO audit_? (at) VALUES (now())",
+			verb:  "INSERT",
+		},
+		{
+			name:  "empty",
+			query: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verb, tables := ClassifySQL(tt.query)
+			if verb != tt.verb || !reflect.DeepEqual(tables, tt.tables) {
+				t.Errorf("ClassifySQL(%q) = %q, %q, want %q, %q", tt.query, verb, tables, tt.verb, tt.tables)
+			}
+		})
+	}
+}
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		val  string
+		want Statement
+	}{
+		{
+			name: "literal",
+			val:  `"SELECT * FROM users"`,
+			want: Statement{Query: "SELECT * FROM users", Verb: "SELECT", Tables: []string{"users"}},
+		},
+		{
+			name: "concatenation",
+			val:  `"SELECT * FROM users WHERE id = "<var id.id>`,
+			want: Statement{Query: "SELECT * FROM users WHERE id = <var id.id>", Verb: "SELECT", Tables: []string{"users"}, Dynamic: true},
+		},
+		{
+			name: "dynamic table name",
+			val:  `"INSERT INTO audit_"<var table.table>" (at) VALUES (now())"`,
+			want: Statement{Query: "INSERT INTO audit_<var table.table> (at) VALUES (now())", Verb: "INSERT", Dynamic: true},
+		},
+		{
+			name: "unresolved",
+			val:  `<could not resolve>`,
+			want: Statement{Query: "<could not resolve>", Dynamic: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseStatement(tt.val); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStatement(%q) = %+v, want %+v", tt.val, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []Statement
+	}{
+		{
+			name:  "concatenation operator",
+			query: `"SELECT first || ' ' || last FROM users WHERE id = $1"`,
+			want: []Statement{
+				{Query: "SELECT first || ' ' || last FROM users WHERE id = $1", Verb: "SELECT", Tables: []string{"users"}},
+			},
+		},
+		{
+			name:  "multiple values",
+			query: `"SELECT * FROM a" ||  "DELETE FROM b WHERE x = 'y || z'"`,
+			want: []Statement{
+				{Query: "SELECT * FROM a", Verb: "SELECT", Tables: []string{"a"}},
+				{Query: "DELETE FROM b WHERE x = 'y || z'", Verb: "DELETE", Tables: []string{"b"}},
+			},
+		},
+		{
+			name:  "unresolved",
+			query: "",
+			want:  []Statement{{Dynamic: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RouteMatch{
+				Indicator: indicator.Indicator{SQL: true, Params: []indicator.RouteParam{{Name: "query"}}},
+				Params:    map[string]string{"query": tt.query},
+			}
+			if got := r.Statements(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Statements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package match
+
+import "time"
+
+// Sources of suppressions
+const (
+	InlineSuppression   = "inline"
+	BaselineSuppression = "baseline"
+)
+
+// SuppressionDateLayout is the layout of suppression expiry dates
+const SuppressionDateLayout = "2006-01-02"
+
+// Suppression records why a match is accepted and not reported by default
+type Suppression struct {
+	Source  string
+	Reason  string `json:",omitempty"`
+	Expires string `json:",omitempty"`
+}
+
+// Expired reports whether the suppression expired before now. Suppressions with an expiry date that cannot be parsed
+// are treated as expired, so that a typo does not suppress a match forever
+func (s *Suppression) Expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, err := time.Parse(SuppressionDateLayout, s.Expires)
+	if err != nil {
+		return true
+	}
+	// A suppression is valid through the whole day it expires
+	return now.After(expires.AddDate(0, 0, 1))
+}
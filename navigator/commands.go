@@ -0,0 +1,147 @@
+package navigator
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/wallylib"
+	"go/ast"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// matchLiteral returns the match of a composite literal matched by a struct indicator, as in &cobra.Command{...}.
+// Literals of CLI frameworks are recorded as commands, including those only matched by HandlerIndicators
+func (n *Navigator) matchLiteral(pass *analysis.Pass, lit *ast.CompositeLit, ignoreComments map[string]map[int]ignoreComment) *match.RouteMatch {
+	t := pass.TypesInfo.TypeOf(lit)
+	route := wallylib.MatchLiteral(t, pass.Pkg, n.RouteIndicators)
+	if route == nil {
+		if handlerRoute := wallylib.MatchLiteral(t, pass.Pkg, n.HandlerIndicators); n.RunSSA && handlerRoute != nil && isCLIFramework(handlerRoute.Framework) {
+			n.recordCommand(handlerRoute.Framework, lit, pass, literalHandler(lit, handlerRoute.HandlerFields), "")
+		}
+		return nil
+	}
+
+	pos := pass.Fset.Position(lit.Pos())
+	if !n.PassesExclusions(pos, route.Package) {
+		return nil
+	}
+
+	funcMatch := match.NewRouteMatch(*route, pos)
+	funcMatch.Module = n.GetModuleName(pass.Pkg)
+	funcMatch.Params = wallylib.ResolveLiteralParams(route.Params, lit, pass)
+	handler := literalHandler(lit, route.HandlerFields)
+	if handler != nil {
+		funcMatch.Handler = wallylib.DescribeHandlerExpr(handler, pass)
+	}
+
+	decl := enclosingFuncDecl(pass, lit)
+	if decl != nil {
+		funcMatch.EnclosedBy = fmt.Sprintf("%s.%s", pass.Pkg.Name(), decl.Name.String())
+	}
+	if n.RunSSA {
+		// Literals of package level variables are enclosed by the init function of the package
+		if ssapkg := n.SSAPkgFromTypesPackage(pass.Pkg); ssapkg != nil {
+			if fn := enclosingSSAFunc(pass, ssapkg, lit.Pos()); fn != nil {
+				funcMatch.EnclosedBy = fmt.Sprintf("%s.%s", pass.Pkg.Name(), fn.Name())
+				funcMatch.SSA.EnclosedByFunc = fn
+			}
+			if handler != nil {
+				funcMatch.SSA.HandlerFunc = n.handlerFunc(pass, ssapkg, handler)
+			}
+		}
+	}
+
+	var enclosingDecl string
+	if decl != nil {
+		enclosingDecl = fmt.Sprintf("%s.%s", pass.Pkg.Path(), decl.Name.String())
+	}
+	position := funcMatch.ModuleRelativePosition(n.GetModuleDir(pass.Pkg))
+	funcMatch.MatchId = match.ContentID(route.Id, position, enclosingDecl, funcMatch.Params)
+	funcMatch.Suppression = inlineSuppression(ignoreComments[pos.Filename], pos.Line, route.Id)
+
+	if isCLIFramework(route.Framework) {
+		n.recordCommand(route.Framework, lit, pass, handler, funcMatch.MatchId)
+	}
+	return &funcMatch
+}
+
+// recordCommand records a CLI command declared by site, reported with the match matchID, if any
+func (n *Navigator) recordCommand(framework string, site ast.Node, pass *analysis.Pass, handler ast.Expr, matchID string) {
+	n.commands = append(n.commands, &wallylib.Command{
+		Framework: framework,
+		Site:      site,
+		Pass:      pass,
+		Handler:   handler,
+	})
+	n.commandIDs = append(n.commandIDs, matchID)
+}
+
+// resolveCommands arranges the recorded CLI commands in a tree, and sets the command path, parent and flags of their
+// matches. With SSA, the handlers of commands are recorded to label the call paths going through them
+func (n *Navigator) resolveCommands() {
+	if len(n.commands) == 0 {
+		return
+	}
+	wallylib.ResolveCommands(n.commands, n.passes)
+
+	matches := make(map[string]int, len(n.RouteMatches))
+	for i, m := range n.RouteMatches {
+		matches[m.MatchId] = i
+	}
+	for i, c := range n.commands {
+		// Tickers read by the function running a command are kept as jobs
+		if c.Handler != nil {
+			n.recordEntryFunc(c.Pass, c.Handler)
+		} else if ce, ok := c.Site.(*ast.CallExpr); ok {
+			n.recordEntryPos(enclosingFuncPos(c.Pass, ce))
+		}
+
+		path := c.Path(n.commands)
+		if j, ok := matches[n.commandIDs[i]]; ok && n.commandIDs[i] != "" {
+			m := &n.RouteMatches[j]
+			m.Command = path
+			m.Flags = c.Flags
+			if c.Parent >= 0 {
+				m.Parent = n.commandIDs[c.Parent]
+			}
+		}
+
+		if !n.RunSSA {
+			continue
+		}
+		n.recordEntryHandler(n.commandHandler(c), fmt.Sprintf("%s: %s", indicator.CommandEntry, path))
+	}
+}
+
+// commandHandler returns the SSA function run by a command: the handler set in its literal, or the function parsing
+// its flags
+func (n *Navigator) commandHandler(c *wallylib.Command) *ssa.Function {
+	ssapkg := n.SSAPkgFromTypesPackage(c.Pass.Pkg)
+	if ssapkg == nil {
+		return nil
+	}
+
+	if ce, ok := c.Site.(*ast.CallExpr); ok && c.Handler == nil {
+		return enclosingSSAFunc(c.Pass, ssapkg, ce.Pos())
+	}
+	return n.handlerFunc(c.Pass, ssapkg, c.Handler)
+}
+
+// literalHandler returns the value of the first of fields set in lit
+func literalHandler(lit *ast.CompositeLit, fields []string) ast.Expr {
+	for _, field := range fields {
+		if value := wallylib.LiteralField(lit, field); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+func isCLIFramework(framework string) bool {
+	switch framework {
+	case indicator.CobraFramework, indicator.UrfaveCLIFramework, indicator.FlagFramework:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package navigator
+
+import (
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"slices"
+	"testing"
+)
+
+func TestCommandTree(t *testing.T) {
+	nav := NewNavigator(0, indicator.InitIndicators(nil, false))
+	nav.MapRoutes([]string{"../cmd"})
+
+	commands := make(map[string]match.RouteMatch)
+	for _, m := range nav.RouteMatches {
+		if m.Command != "" {
+			commands[m.Command] = m
+		}
+	}
+
+	tests := []struct {
+		command string
+		parent  string
+		flags   []string
+	}{
+		{command: "wally", flags: []string{"--verbose"}},
+		{command: "wally map", parent: "wally", flags: []string{"--paths", "--ssa", "--verbose"}},
+		{command: "wally map search", parent: "wally map", flags: []string{"--func", "--paths", "--pkg"}},
+		{command: "wally topology", parent: "wally", flags: []string{"--format", "--host", "--out"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			m, ok := commands[tt.command]
+			if !ok {
+				t.Fatalf("command %q not found", tt.command)
+			}
+			if tt.parent == "" {
+				if m.Parent != "" {
+					t.Errorf("parent = %q, want none", m.Parent)
+				}
+			} else if parent := commands[tt.parent]; m.Parent == "" || m.Parent != parent.MatchId {
+				t.Errorf("parent = %q, want %q (%s)", m.Parent, parent.MatchId, tt.parent)
+			}
+			for _, f := range tt.flags {
+				if !slices.Contains(m.Flags, f) {
+					t.Errorf("flags %v do not include %s", m.Flags, f)
+				}
+			}
+		})
+	}
+}
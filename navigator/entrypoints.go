@@ -0,0 +1,335 @@
+package navigator
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/wallylib"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// matchGoStmt returns the match of a go statement matched by a Go indicator, as in go worker.Run(ctx) in main. The
+// function started by the statement is the handler of the match
+func (n *Navigator) matchGoStmt(pass *analysis.Pass, gs *ast.GoStmt, ignoreComments map[string]map[int]ignoreComment) *match.RouteMatch {
+	decl := enclosingFuncDecl(pass, gs)
+	if decl == nil || decl.Recv != nil {
+		return nil
+	}
+	funcInfo := &wallylib.FuncInfo{
+		Package: pass.Pkg.Path(),
+		Name:    decl.Name.Name,
+		EnclosedBy: &wallylib.FuncDecl{
+			Pkg:  pass.Pkg,
+			Decl: decl,
+		},
+	}
+
+	route := funcInfo.MatchGo(n.RouteIndicators)
+	if route == nil {
+		if handlerRoute := funcInfo.MatchGo(n.HandlerIndicators); n.RunSSA && handlerRoute != nil {
+			n.recordEntryHandler(n.goStmtHandler(pass, gs), entryLabel(handlerRoute, nil))
+		}
+		return nil
+	}
+
+	pos := pass.Fset.Position(gs.Pos())
+	if !n.PassesExclusions(pos, funcInfo.Package) {
+		return nil
+	}
+
+	funcMatch := match.NewRouteMatch(*route, pos)
+	funcMatch.Module = n.GetModuleName(pass.Pkg)
+	funcMatch.Params = map[string]string{}
+	funcMatch.Handler = wallylib.DescribeHandlerExpr(gs.Call.Fun, pass)
+	funcMatch.EnclosedBy = fmt.Sprintf("%s.%s", pass.Pkg.Name(), decl.Name.String())
+	if n.RunSSA {
+		if ssapkg := n.SSAPkgFromTypesPackage(pass.Pkg); ssapkg != nil {
+			if fn := enclosingSSAFunc(pass, ssapkg, gs.Pos()); fn != nil {
+				funcMatch.EnclosedBy = fmt.Sprintf("%s.%s", pass.Pkg.Name(), fn.Name())
+				funcMatch.SSA.EnclosedByFunc = fn
+			}
+		}
+		funcMatch.SSA.HandlerFunc = n.goStmtHandler(pass, gs)
+		n.recordEntryHandler(funcMatch.SSA.HandlerFunc, entryLabel(route, nil))
+	}
+
+	enclosingDecl := fmt.Sprintf("%s.%s", pass.Pkg.Path(), decl.Name.String())
+	position := funcMatch.ModuleRelativePosition(n.GetModuleDir(pass.Pkg))
+	funcMatch.MatchId = match.ContentID(route.Id, position, enclosingDecl, funcMatch.Params)
+	funcMatch.Suppression = inlineSuppression(ignoreComments[pos.Filename], pos.Line, route.Id)
+	return &funcMatch
+}
+
+// goStmtHandler returns the SSA function started by a go statement
+func (n *Navigator) goStmtHandler(pass *analysis.Pass, gs *ast.GoStmt) *ssa.Function {
+	ssapkg := n.SSAPkgFromTypesPackage(pass.Pkg)
+	if ssapkg == nil {
+		return nil
+	}
+	return n.handlerFunc(pass, ssapkg, gs.Call.Fun)
+}
+
+// jobRead is a job read where it is created, as done with tickers, reported with the match matchID if any
+type jobRead struct {
+	ce      *ast.CallExpr
+	pass    *analysis.Pass
+	route   *indicator.Indicator
+	params  map[string]string
+	matchID string
+}
+
+// recordJobHandler records the function run by a scheduled job registered by ce, reported with the match matchID if
+// any: the handler passed to the call, or the function enclosing it for tickers, which are read where they are
+// created. Tickers are recorded once all packages have been analyzed, by resolveJobReads
+func (n *Navigator) recordJobHandler(pass *analysis.Pass, ce *ast.CallExpr, route *indicator.Indicator, params map[string]string, matchID string) {
+	arg, method := wallylib.HandlerArg(ce, pass)
+	switch {
+	case method != nil:
+		n.recordEntryPos(method.Pos())
+	case arg != nil:
+		n.recordEntryFunc(pass, arg)
+	default:
+		n.jobReads = append(n.jobReads, jobRead{ce: ce, pass: pass, route: route, params: params, matchID: matchID})
+		return
+	}
+
+	if !n.RunSSA {
+		return
+	}
+	if ssapkg := n.SSAPkgFromTypesPackage(pass.Pkg); ssapkg != nil {
+		n.recordEntryHandler(n.callHandlerFunc(pass, ssapkg, ce), entryLabel(route, params))
+	}
+}
+
+// resolveJobReads keeps the tickers and other jobs read where they are created only when they are read by a function
+// started by a go statement, run by a command or another job, or by a main function. Tickers read elsewhere, such as
+// in request handlers, are mostly timeouts or polling loops rather than jobs. With SSA, the functions reading the
+// jobs that are kept are recorded to label call paths
+func (n *Navigator) resolveJobReads() {
+	dropped := make(map[string]bool)
+	for _, r := range n.jobReads {
+		if !n.entryFuncs[enclosingFuncPos(r.pass, r.ce)] {
+			if r.matchID != "" {
+				dropped[r.matchID] = true
+			}
+			continue
+		}
+		if !n.RunSSA {
+			continue
+		}
+		if ssapkg := n.SSAPkgFromTypesPackage(r.pass.Pkg); ssapkg != nil {
+			n.recordEntryHandler(enclosingSSAFunc(r.pass, ssapkg, r.ce.Pos()), entryLabel(r.route, r.params))
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	matches := n.RouteMatches[:0]
+	for _, m := range n.RouteMatches {
+		if !dropped[m.MatchId] {
+			matches = append(matches, m)
+		}
+	}
+	n.RouteMatches = matches
+}
+
+// recordEntryFunc records the function of a handler expression, such as the function started by a go statement, as
+// one of entryFuncs
+func (n *Navigator) recordEntryFunc(pass *analysis.Pass, expr ast.Expr) {
+	switch h := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		n.recordEntryPos(h.Pos())
+	case *ast.Ident:
+		if fn, ok := pass.TypesInfo.ObjectOf(h).(*types.Func); ok {
+			n.recordEntryPos(fn.Origin().Pos())
+		}
+	case *ast.SelectorExpr:
+		if fn, ok := pass.TypesInfo.ObjectOf(h.Sel).(*types.Func); ok {
+			n.recordEntryPos(fn.Origin().Pos())
+		}
+	case *ast.CallExpr:
+		// Conversions such as http.HandlerFunc(h) wrap the actual function
+		if tv, ok := pass.TypesInfo.Types[h.Fun]; ok && tv.IsType() && len(h.Args) == 1 {
+			n.recordEntryFunc(pass, h.Args[0])
+		}
+	}
+}
+
+func (n *Navigator) recordEntryPos(pos token.Pos) {
+	if !pos.IsValid() {
+		return
+	}
+	if n.entryFuncs == nil {
+		n.entryFuncs = make(map[token.Pos]bool)
+	}
+	n.entryFuncs[pos] = true
+}
+
+// enclosingFuncPos returns the position identifying the function enclosing node, as recorded by recordEntryFunc: that
+// of the innermost function literal, or of the name of the declared function
+func enclosingFuncPos(pass *analysis.Pass, node ast.Node) token.Pos {
+	file := passFile(pass, node.Pos())
+	if file == nil {
+		return token.NoPos
+	}
+	path, _ := astutil.PathEnclosingInterval(file, node.Pos(), node.End())
+	for _, p := range path {
+		switch f := p.(type) {
+		case *ast.FuncLit:
+			return f.Pos()
+		case *ast.FuncDecl:
+			return f.Name.Pos()
+		}
+	}
+	return token.NoPos
+}
+
+// callHandlerFunc returns the SSA function of the handler passed to ce, as found by wallylib.HandlerArg
+func (n *Navigator) callHandlerFunc(pass *analysis.Pass, ssapkg *ssa.Package, ce *ast.CallExpr) *ssa.Function {
+	arg, method := wallylib.HandlerArg(ce, pass)
+	switch {
+	case method != nil:
+		return n.SSA.Program.FuncValue(method)
+	case arg != nil:
+		return n.handlerFunc(pass, ssapkg, arg)
+	}
+	return nil
+}
+
+// recordEntryHandler records the label of a function run by an entry point, used to label call path nodes. Functions
+// run by several entry points, such as a worker reading a ticker, get the labels of all of them
+func (n *Navigator) recordEntryHandler(fn *ssa.Function, label string) {
+	if fn == nil {
+		return
+	}
+	if n.EntryHandlers == nil {
+		n.EntryHandlers = make(map[*ssa.Function]string)
+	}
+	existing := n.EntryHandlers[fn]
+	switch {
+	case existing == "":
+		n.EntryHandlers[fn] = label
+	case !slices.Contains(strings.Split(existing, ", "), label):
+		n.EntryHandlers[fn] = existing + ", " + label
+	}
+}
+
+// entryLabel returns the label of the functions run by matches of route, as in job: @every 1m. The first param of
+// the indicator, such as the schedule of a job, is added to the kind of entry point when resolved
+func entryLabel(route *indicator.Indicator, params map[string]string) string {
+	label := route.Kind()
+	if len(route.Params) == 0 {
+		return label
+	}
+	key := route.Params[0].Name
+	if route.Params[0].Field != "" {
+		key = route.Params[0].Field
+	}
+	val := params[key]
+	if unquoted, err := strconv.Unquote(val); err == nil {
+		val = unquoted
+	}
+	if val == "" {
+		return label
+	}
+	return fmt.Sprintf("%s: %s", label, val)
+}
+
+// handlerFunc returns the SSA function of a handler expression: a function literal, a function or a method value, or
+// the ServeHTTP method of a value implementing http.Handler. Conversions such as http.HandlerFunc(h) are followed
+func (n *Navigator) handlerFunc(pass *analysis.Pass, ssapkg *ssa.Package, expr ast.Expr) *ssa.Function {
+	switch h := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		return enclosingSSAFunc(pass, ssapkg, h.Body.Lbrace)
+	case *ast.Ident:
+		if fn, ok := pass.TypesInfo.ObjectOf(h).(*types.Func); ok {
+			return n.SSA.Program.FuncValue(fn)
+		}
+	case *ast.SelectorExpr:
+		obj := pass.TypesInfo.ObjectOf(h.Sel)
+		if sel, ok := pass.TypesInfo.Selections[h]; ok {
+			obj = sel.Obj()
+		}
+		if fn, ok := obj.(*types.Func); ok {
+			return n.SSA.Program.FuncValue(fn)
+		}
+	case *ast.CallExpr:
+		if tv, ok := pass.TypesInfo.Types[h.Fun]; ok && tv.IsType() && len(h.Args) == 1 {
+			return n.handlerFunc(pass, ssapkg, h.Args[0])
+		}
+	}
+	// Methods with a pointer receiver are included, as in &handler{}
+	if t := pass.TypesInfo.TypeOf(expr); t != nil && !types.IsInterface(t) {
+		if obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "ServeHTTP"); obj != nil {
+			if fn, ok := obj.(*types.Func); ok {
+				return n.SSA.Program.FuncValue(fn)
+			}
+		}
+	}
+	return nil
+}
+
+// enclosingSSAFunc returns the SSA function enclosing pos
+func enclosingSSAFunc(pass *analysis.Pass, ssapkg *ssa.Package, pos token.Pos) *ssa.Function {
+	file := passFile(pass, pos)
+	if file == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	return ssa.EnclosingFunction(ssapkg, path)
+}
+
+// passFile returns the file of pass holding pos. Commands and jobs are resolved once all packages have been analyzed,
+// when the files indexed by File are those of the last package, so the file is looked up in the files of the pass
+func passFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// resolveEgressRoutes sets the routes reaching each outbound call, which are the matches whose handlers are found in
+// the call paths of the egress match
+func (n *Navigator) resolveEgressRoutes() {
+	handlers := make(map[*ssa.Function][]string)
+	for _, m := range n.RouteMatches {
+		if m.Indicator.IndicatorType != indicator.Caller && m.SSA != nil && m.SSA.HandlerFunc != nil {
+			handlers[m.SSA.HandlerFunc] = append(handlers[m.SSA.HandlerFunc], m.MatchId)
+		}
+	}
+
+	for i := range n.RouteMatches {
+		m := &n.RouteMatches[i]
+		if m.Indicator.Egress == "" || m.SSA == nil || m.SSA.CallPaths == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		m.ReachedFrom = []string{}
+		for _, path := range m.SSA.CallPaths.Paths {
+			for _, node := range path.Nodes {
+				if node.Caller == nil || node.Caller.Func == nil {
+					continue
+				}
+				for _, id := range handlers[node.Caller.Func] {
+					if !seen[id] {
+						seen[id] = true
+						m.ReachedFrom = append(m.ReachedFrom, id)
+					}
+				}
+			}
+		}
+		sort.Strings(m.ReachedFrom)
+	}
+}
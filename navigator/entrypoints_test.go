@@ -0,0 +1,36 @@
+package navigator
+
+import (
+	"github.com/hex0punk/wally/indicator"
+	"path/filepath"
+	"testing"
+)
+
+func TestJobReads(t *testing.T) {
+	nav := NewNavigator(0, indicator.InitIndicators(nil, false))
+	nav.MapRoutes([]string{"./testdata/jobs/..."})
+
+	tickers := make(map[string]bool)
+	for _, m := range nav.RouteMatches {
+		if m.Indicator.Package == "time" {
+			tickers[filepath.Base(filepath.Dir(m.Pos.Filename))] = true
+		}
+	}
+
+	tests := []struct {
+		pkg  string
+		want bool
+	}{
+		// Read by a function parsing flags
+		{pkg: "cli", want: true},
+		// Started by a go statement
+		{pkg: "queue", want: true},
+		// Read by a request handler
+		{pkg: "web", want: false},
+	}
+	for _, tt := range tests {
+		if tickers[tt.pkg] != tt.want {
+			t.Errorf("ticker in %s reported = %v, want %v", tt.pkg, tickers[tt.pkg], tt.want)
+		}
+	}
+}
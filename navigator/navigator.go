@@ -12,6 +12,7 @@ import (
 	"github.com/hex0punk/wally/reporter"
 	"github.com/hex0punk/wally/wallylib"
 	"github.com/hex0punk/wally/wallylib/callmapper"
+	"github.com/hex0punk/wally/wallynode"
 	"go/ast"
 	"go/token"
 	"go/types"
@@ -31,6 +32,7 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -45,6 +47,52 @@ type Navigator struct {
 	Packages        []*packages.Package
 	CallgraphAlg    string
 	Exclusions      Exclusions
+	// Baseline holds matches accepted as existing risk, which are suppressed like matches with a wally:ignore comment
+	Baseline *Baseline
+	// ShowSuppressed keeps suppressed matches in RouteMatches, with their Suppression set
+	ShowSuppressed bool
+	// SuppressedMatches holds suppressed matches removed from RouteMatches
+	SuppressedMatches []match.RouteMatch
+	SuppressedCount   int
+	// AuthMiddleware lists functions that authenticate requests, which are looked for around route registrations
+	AuthMiddleware []string
+	// UnauthenticatedOnly keeps only the routes not wrapped by any of the AuthMiddleware in RouteMatches
+	UnauthenticatedOnly bool
+	// RecoveredHandlers holds handlers registered behind middleware that recovers from panics, which makes call
+	// paths going through them recoverable
+	RecoveredHandlers map[*ssa.Function]bool
+	// HandlerIndicators are route indicators that are not reported, but used to find handlers registered behind
+	// recovery middleware and the handlers of CLI commands and jobs, as when searching for calls to a single function
+	HandlerIndicators []indicator.Indicator
+	// EntryHandlers maps the functions run by CLI commands, scheduled jobs and workers to a label such as
+	// command: wally map, which labels the nodes of call paths going through them
+	EntryHandlers map[*ssa.Function]string
+
+	// Route registrations and function declarations kept to resolve the inputs and responses of handlers once all
+	// packages have been analyzed
+	handlerCalls map[string]handlerCall
+	funcDecls    map[*types.Func]funcDecl
+	// loadedPackages indexes the loaded packages and their dependencies by types package
+	loadedPackages map[*types.Package]*packages.Package
+	// CLI commands, along with the ID of the match of each command if it is reported, and the analyzed passes their
+	// parents and flags are resolved from
+	commands   []*wallylib.Command
+	commandIDs []string
+	passes     []*analysis.Pass
+	// Jobs read where they are created, such as tickers, which are only kept when read by one of entryFuncs, the
+	// functions started by go statements, run by commands or jobs, or main functions, found by their position
+	jobReads   []jobRead
+	entryFuncs map[token.Pos]bool
+}
+
+type handlerCall struct {
+	ce   *ast.CallExpr
+	pass *analysis.Pass
+}
+
+type funcDecl struct {
+	decl *ast.FuncDecl
+	pass *analysis.Pass
 }
 
 type Exclusions struct {
@@ -174,6 +222,86 @@ func (n *Navigator) MapRoutes(paths []string) {
 			}
 		}
 	}
+
+	n.resolveHandlers()
+	n.resolveCommands()
+	n.resolveJobReads()
+	match.SortMatches(n.RouteMatches)
+	n.applySuppressions()
+
+	if n.UnauthenticatedOnly {
+		n.filterAuthenticated()
+	}
+}
+
+// indexFuncDecls records the function declarations of a pass, so that handlers and the functions they call can be
+// found across packages
+func (n *Navigator) indexFuncDecls(pass *analysis.Pass) {
+	if n.funcDecls == nil {
+		n.funcDecls = make(map[*types.Func]funcDecl)
+		n.handlerCalls = make(map[string]handlerCall)
+	}
+	for _, file := range pass.Files {
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+				n.funcDecls[fn] = funcDecl{decl: decl, pass: pass}
+			}
+			if pass.Pkg.Name() == "main" && decl.Name.Name == "main" && decl.Recv == nil {
+				n.recordEntryFunc(pass, decl.Name)
+			}
+		}
+	}
+}
+
+// resolveHandlers sets the request inputs read and the responses written by the handler of every HTTP or RPC match,
+// along with the service methods behind go-kit endpoints. It runs after all packages have been analyzed, as handlers
+// and the functions they call can be declared in any of them
+func (n *Navigator) resolveHandlers() {
+	source := func(fn *types.Func) (*ast.FuncDecl, *analysis.Pass) {
+		fd, ok := n.funcDecls[fn]
+		if !ok {
+			return nil, nil
+		}
+		return fd.decl, fd.pass
+	}
+	for i := range n.RouteMatches {
+		hc, ok := n.handlerCalls[n.RouteMatches[i].MatchId]
+		if !ok {
+			continue
+		}
+		if handler, method := wallylib.ResolveGoKitHandler(hc.ce, hc.pass, source); handler != "" {
+			n.RouteMatches[i].Handler = handler
+			if n.RunSSA {
+				n.RouteMatches[i].SSA.HandlerFunc = nil
+				if method != nil {
+					n.RouteMatches[i].SSA.HandlerFunc = n.SSA.Program.FuncValue(method)
+				}
+			}
+		}
+		// Handlers of other entry points do not serve HTTP requests
+		if n.RouteMatches[i].Indicator.IndicatorType == indicator.EntryPoint {
+			continue
+		}
+		n.RouteMatches[i].Inputs = wallylib.ResolveInputs(hc.ce, hc.pass, source)
+		n.RouteMatches[i].Responses = wallylib.ResolveResponses(hc.ce, hc.pass, source)
+	}
+}
+
+// filterAuthenticated keeps only routes with no auth middleware wrapping them. Other matches, such as callers or
+// entry points, are dropped
+func (n *Navigator) filterAuthenticated() {
+	var results []match.RouteMatch
+	for _, m := range n.RouteMatches {
+		if m.Indicator.IndicatorType == indicator.Service && len(m.Auth) == 0 {
+			results = append(results, m)
+		}
+	}
+	n.Logger.Info("Filtered authenticated matches", "removed", len(n.RouteMatches)-len(results))
+	n.RouteMatches = results
 }
 
 func LoadPackages(paths []string) []*packages.Package {
@@ -221,15 +349,34 @@ func (n *Navigator) Run(pass *analysis.Pass) (interface{}, error) {
 		(*ast.GenDecl)(nil),
 		(*ast.AssignStmt)(nil),
 		(*ast.DeclStmt)(nil),
+		(*ast.CompositeLit)(nil),
+		(*ast.GoStmt)(nil),
 	}
 
 	var results []match.RouteMatch
+	ignoreComments := collectIgnoreComments(pass)
+	n.indexFuncDecls(pass)
+	n.passes = append(n.passes, pass)
 
 	// this is basically the same as ast.Inspect(), only we don't return a
 	// boolean anymore as it'll visit all the nodes based on the filter.
 	inspecting.Preorder(nodeFilter, func(node ast.Node) {
 		n.cacheVariables(node, pass)
 
+		if lit, ok := node.(*ast.CompositeLit); ok {
+			if m := n.matchLiteral(pass, lit, ignoreComments); m != nil {
+				results = append(results, *m)
+			}
+			return
+		}
+		if gs, ok := node.(*ast.GoStmt); ok {
+			n.recordEntryFunc(pass, gs.Call.Fun)
+			if m := n.matchGoStmt(pass, gs, ignoreComments); m != nil {
+				results = append(results, *m)
+			}
+			return
+		}
+
 		ce, ok := node.(*ast.CallExpr)
 		if !ok {
 			return
@@ -250,6 +397,10 @@ func (n *Navigator) Run(pass *analysis.Pass) (interface{}, error) {
 
 		// This will be used for funcInfo.Match
 		decl := callMapper.EnclosingFunc(ce)
+		if decl == nil {
+			// Calls taking function literals, as in mux.HandleFunc("/", func(...) {...}), are not always mapped
+			decl = enclosingFuncDecl(pass, ce)
+		}
 		if decl != nil {
 			funcInfo.EnclosedBy = &wallylib.FuncDecl{
 				Pkg:  pass.Pkg,
@@ -258,7 +409,23 @@ func (n *Navigator) Run(pass *analysis.Pass) (interface{}, error) {
 		}
 
 		route := funcInfo.Match(n.RouteIndicators)
+		if route != nil && route.Framework != "" && decl != nil {
+			// Calls made by generated service constructors, such as RegisterService in RegisterXxxServer, are matched
+			// where the constructors are called instead
+			if fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok && wallylib.IsRPCConstructor(route.Framework, fn.Name(), fn.Type().(*types.Signature)) {
+				return
+			}
+		}
 		if route == nil {
+			if handlerRoute := funcInfo.Match(n.HandlerIndicators); n.RunSSA && handlerRoute != nil {
+				if handlerRoute.Framework == indicator.FlagFramework {
+					n.recordCommand(handlerRoute.Framework, ce, pass, nil, "")
+				} else if handlerRoute.EntryKind == indicator.JobEntry {
+					n.recordJobHandler(pass, ce, handlerRoute, wallylib.ResolveParams(handlerRoute.Params, funcInfo.Signature, ce, pass), "")
+				} else {
+					n.recordRecoveredHandlers(pass, ce)
+				}
+			}
 			// Don't keep going deeper in the node if there are no matches by now?
 			return
 		}
@@ -266,15 +433,24 @@ func (n *Navigator) Run(pass *analysis.Pass) (interface{}, error) {
 		// Whether we are able to get params or not we have a match
 		funcMatch := match.NewRouteMatch(*route, pos)
 
-		if modName := n.GetModuleName(funcInfo.Pkg); modName != "" {
+		// Outbound calls belong to the module making them rather than to the module of the client
+		if modName := n.GetModuleName(funcInfo.Pkg); modName != "" && route.IndicatorType != indicator.Caller {
 			funcMatch.Module = modName
 		} else {
-			funcMatch.Module = n.GetModuleName(funcInfo.EnclosedBy.Pkg)
+			funcMatch.Module = n.GetModuleName(pass.Pkg)
 		}
 
 		// Now try to get the params for methods, path, etc.
 		funcMatch.Params = wallylib.ResolveParams(route.Params, funcInfo.Signature, ce, pass)
-
+		funcMatch.Handler = wallylib.ResolveHandler(ce, pass)
+		var handlerDecl *types.Func
+		if (route.Framework == indicator.FlagFramework || route.EntryKind == indicator.JobEntry) && funcMatch.Handler == "" && decl != nil {
+			// Flags are parsed by the function running the command, and tickers are read by the function running the job
+			if fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+				funcMatch.Handler = fn.FullName()
+				handlerDecl = fn
+			}
+		}
 		//Get the enclosing func
 		if n.RunSSA {
 			ssapkg := n.SSAPkgFromTypesPackage(pass.Pkg)
@@ -286,10 +462,25 @@ func (n *Navigator) Run(pass *analysis.Pass) (interface{}, error) {
 
 					if funcMatch.SSA.SSAInstruction != nil {
 						funcMatch.SSA.SSAFunc = wallylib.GetFunctionFromCallInstruction(funcMatch.SSA.SSAInstruction)
+						funcMatch.Middleware = n.resolveMiddleware(funcMatch.SSA.SSAInstruction)
 					} else {
 						n.Logger.Debug("unable to get SSA instruction for function", "function", ssaEnclosingFunc.Name())
 					}
 				}
+				funcMatch.SSA.HandlerFunc = n.callHandlerFunc(pass, ssapkg, ce)
+			}
+			if handlerDecl != nil {
+				funcMatch.SSA.HandlerFunc = n.SSA.Program.FuncValue(handlerDecl)
+			}
+		}
+
+		// Auth middleware are taken from the chain resolved from SSA, and from the AST of the registration without SSA.
+		// Only routes serve requests that can be authenticated
+		if len(n.AuthMiddleware) > 0 && route.IndicatorType == indicator.Service {
+			if funcMatch.Middleware != nil {
+				funcMatch.Auth = wallylib.AuthFromChain(funcMatch.Middleware, n.AuthMiddleware)
+			} else {
+				funcMatch.Auth = wallylib.ResolveAuthMiddleware(ce, decl, n.AuthMiddleware, pass)
 			}
 		}
 
@@ -299,12 +490,122 @@ func (n *Navigator) Run(pass *analysis.Pass) (interface{}, error) {
 			}
 		}
 
-		results = append(results, funcMatch)
+		// The enclosing function used for the ID comes from the AST, so that IDs are the same with and without SSA
+		var enclosingDecl string
+		if decl != nil {
+			enclosingDecl = fmt.Sprintf("%s.%s", pass.Pkg.Path(), decl.Name.String())
+		}
+		position := funcMatch.ModuleRelativePosition(n.GetModuleDir(pass.Pkg))
+
+		funcMatches := []match.RouteMatch{funcMatch}
+		if route.Framework != "" {
+			funcMatches = n.rpcMethodMatches(funcMatch, ce, pass)
+		}
+		for _, m := range funcMatches {
+			m.MatchId = match.ContentID(route.Id, position, enclosingDecl, m.Params)
+			m.Suppression = inlineSuppression(ignoreComments[pos.Filename], pos.Line, route.Id)
+			n.handlerCalls[m.MatchId] = handlerCall{ce: ce, pass: pass}
+			if route.Framework == indicator.FlagFramework {
+				n.recordCommand(route.Framework, ce, pass, nil, m.MatchId)
+			}
+			if route.EntryKind == indicator.JobEntry {
+				n.recordJobHandler(pass, ce, route, m.Params, m.MatchId)
+			}
+
+			results = append(results, m)
+		}
 	})
 
 	return results, nil
 }
 
+// rpcMethodMatches returns a match for each method of the RPC service registered by ce, with the route of the method
+// as param and the method of the registered implementation as handler. The match is returned as is if ce does not
+// register a service or the service could not be resolved
+func (n *Navigator) rpcMethodMatches(funcMatch match.RouteMatch, ce *ast.CallExpr, pass *analysis.Pass) []match.RouteMatch {
+	methods := wallylib.ResolveRPCMethods(funcMatch.Indicator.Framework, ce, pass, n.packageSyntax)
+	if len(methods) == 0 {
+		return []match.RouteMatch{funcMatch}
+	}
+
+	param := "method"
+	if len(funcMatch.Indicator.Params) > 0 && funcMatch.Indicator.Params[0].Name != "" {
+		param = funcMatch.Indicator.Params[0].Name
+	}
+	var res []match.RouteMatch
+	for _, method := range methods {
+		m := funcMatch
+		m.Params = map[string]string{param: strconv.Quote(method.FullMethod)}
+		// Call paths are solved for each match separately
+		ssaCtx := *funcMatch.SSA
+		m.SSA = &ssaCtx
+		m.Handler = ""
+		m.SSA.HandlerFunc = nil
+		if method.Impl != nil {
+			m.Handler = method.Impl.FullName()
+			if n.RunSSA {
+				m.SSA.HandlerFunc = n.SSA.Program.FuncValue(method.Impl)
+			}
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// packageSyntax returns the files and type info of a loaded package, including dependencies of the analyzed packages
+func (n *Navigator) packageSyntax(pkg *types.Package) ([]*ast.File, *types.Info) {
+	if n.loadedPackages == nil {
+		n.loadedPackages = make(map[*types.Package]*packages.Package)
+		packages.Visit(n.Packages, nil, func(p *packages.Package) {
+			if p.Types != nil {
+				n.loadedPackages[p.Types] = p
+			}
+		})
+	}
+	p, ok := n.loadedPackages[pkg]
+	if !ok {
+		return nil, nil
+	}
+	return p.Syntax, p.TypesInfo
+}
+
+// resolveMiddleware returns the middleware chain of the route registered by call, and records the handlers of the
+// route if any of the middleware recovers from panics
+func (n *Navigator) resolveMiddleware(call ssa.CallInstruction) []wallylib.Middleware {
+	chain, handlers := wallylib.ResolveMiddlewareChain(call)
+	recovered := false
+	for i := range chain {
+		chain[i].Recovers = wallynode.RecoversPanics(chain[i].Func)
+		recovered = recovered || chain[i].Recovers
+	}
+
+	if recovered {
+		if n.RecoveredHandlers == nil {
+			n.RecoveredHandlers = make(map[*ssa.Function]bool)
+		}
+		for _, h := range handlers {
+			n.RecoveredHandlers[h] = true
+		}
+	}
+	return chain
+}
+
+// recordRecoveredHandlers resolves the middleware of a route registration that is not reported as a match, so that
+// handlers registered behind recovery middleware are known when solving call paths
+func (n *Navigator) recordRecoveredHandlers(pass *analysis.Pass, ce *ast.CallExpr) {
+	ssapkg := n.SSAPkgFromTypesPackage(pass.Pkg)
+	if ssapkg == nil {
+		return
+	}
+	enclosingFunc := GetEnclosingFuncWithSSA(pass, ce, ssapkg)
+	if enclosingFunc == nil {
+		return
+	}
+	if call := n.GetCallInstructionFromSSAFunc(enclosingFunc, ce); call != nil {
+		n.resolveMiddleware(call)
+	}
+}
+
 func (n *Navigator) GetCallInstructionFromSSAFunc(enclosingFunc *ssa.Function, expr *ast.CallExpr) ssa.CallInstruction {
 	for _, block := range enclosingFunc.Blocks {
 		for _, instr := range block.Instrs {
@@ -413,6 +714,8 @@ func (n *Navigator) SolveCallPaths(options callmapper.Options) {
 		go func(i int, options callmapper.Options, routeMatch match.RouteMatch) {
 			defer wg.Done()
 			cm := callmapper.NewCallMapper(&routeMatch, n.SSA.Callgraph.Nodes, options)
+			cm.NodeFactory.RecoveredFuncs = n.RecoveredHandlers
+			cm.NodeFactory.EntryFuncs = n.EntryHandlers
 
 			start := time.Now()
 			n.Logger.Debug("Solving paths for match", "match", routeMatch.Pos.String())
@@ -423,12 +726,15 @@ func (n *Navigator) SolveCallPaths(options callmapper.Options) {
 				n.RouteMatches[i].SSA.CallPaths = cm.AllPathsBFS(n.SSA.Callgraph.Nodes[routeMatch.SSA.EnclosedByFunc])
 			}
 
+			n.RouteMatches[i].SSA.CallPaths.SortPaths(routeMatch.MatchId, n.GetModuleDir)
+
 			duration := time.Since(start)
 			n.Logger.Debug("Solved paths for match", "match", routeMatch.Pos.String(), "numPaths", len(n.RouteMatches[i].SSA.CallPaths.Paths), "duration", duration)
 		}(i, options, routeMatch)
 	}
 
 	wg.Wait()
+	n.resolveEgressRoutes()
 }
 
 func (n *Navigator) RecordGlobals(gen *ast.GenDecl, pass *analysis.Pass) {
@@ -458,6 +764,9 @@ func (n *Navigator) RecordGlobals(gen *ast.GenDecl, pass *analysis.Pass) {
 	}
 }
 
+// maxLocalVals limits the values recorded for a local variable, which double with each += on it
+const maxLocalVals = 16
+
 func (n *Navigator) RecordLocals(gen *ast.AssignStmt, pass *analysis.Pass) {
 	for idx, e := range gen.Rhs {
 		idt, ok := gen.Lhs[idx].(*ast.Ident)
@@ -471,6 +780,9 @@ func (n *Navigator) RecordLocals(gen *ast.AssignStmt, pass *analysis.Pass) {
 		}
 
 		res := wallylib.GetValueFromExp(e, pass)
+		if res == "" && gen.Tok == token.ADD_ASSIGN {
+			res = "<could not resolve>"
+		}
 		if res == "" || res == "\"\"" {
 			return
 		}
@@ -479,7 +791,18 @@ func (n *Navigator) RecordLocals(gen *ast.AssignStmt, pass *analysis.Pass) {
 		gv := new(checker.LocalVar)
 		pass.ImportObjectFact(o1, &fact)
 
-		if fact.Vals != nil {
+		if fact.Vals != nil && gen.Tok == token.ADD_ASSIGN {
+			// Concatenations, as in query += " WHERE id = " + id, extend each value of the var. They are often
+			// conditional, so the values they extend are kept as well, up to maxLocalVals values
+			gv.Vals = append([]string{}, fact.Vals...)
+			for _, v := range fact.Vals {
+				if len(gv.Vals) >= maxLocalVals {
+					break
+				}
+				gv.Vals = append(gv.Vals, v+res)
+			}
+			pass.ExportObjectFact(o1, gv)
+		} else if fact.Vals != nil {
 			gv.Vals = fact.Vals
 			gv.Vals = append(gv.Vals, res)
 			pass.ExportObjectFact(o1, gv)
@@ -504,6 +827,15 @@ func (n *Navigator) GetModuleName(typesPkg *types.Package) string {
 	return ""
 }
 
+// GetModuleDir returns the root directory of the module containing typesPkg, if any
+func (n *Navigator) GetModuleDir(typesPkg *types.Package) string {
+	pkg := n.getPackagesPackageFromTypesPackage(typesPkg)
+	if pkg == nil || pkg.Module == nil {
+		return ""
+	}
+	return pkg.Module.Dir
+}
+
 func (n *Navigator) getPackagesPackageFromTypesPackage(typesPkg *types.Package) *packages.Package {
 	typesPkgPath := typesPkg.Path()
 	for _, pkg := range n.Packages {
@@ -535,21 +867,75 @@ func GetEnclosingFuncWithSSA(pass *analysis.Pass, ce *ast.CallExpr, ssaPkg *ssa.
 	return ssa.EnclosingFunction(ssaPkg, ref)
 }
 
+func enclosingFuncDecl(pass *analysis.Pass, node ast.Node) *ast.FuncDecl {
+	file := File(pass, node.Pos())
+	if file == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, node.Pos(), node.End())
+	for _, node := range path {
+		if decl, ok := node.(*ast.FuncDecl); ok {
+			return decl
+		}
+	}
+	return nil
+}
+
 func File(pass *analysis.Pass, pos token.Pos) *ast.File {
 	m := pass.ResultOf[tokenfile.Analyzer].(map[*token.File]*ast.File)
 	return m[pass.Fset.File(pos)]
 }
 
-func (n *Navigator) PrintResults(format string, fileName string) {
-	if format == "json" {
+func (n *Navigator) PrintResults(format string, fileName string, options reporter.Options) {
+	switch format {
+	case "json":
 		if err := reporter.PrintJson(n.RouteMatches, fileName); err != nil {
 			n.Logger.Error("Error printing to json", "error", err.Error())
 		}
-	} else if format == "csv" {
+	case "sarif":
+		if err := reporter.PrintSarif(n.RouteIndicators, n.RouteMatches, fileName); err != nil {
+			n.Logger.Error("Error printing SARIF", "error", err.Error())
+		}
+	case "markdown":
+		if err := reporter.PrintMarkdown(n.RouteMatches, fileName, options.MaxPathsInReport); err != nil {
+			n.Logger.Error("Error printing markdown", "error", err.Error())
+		}
+	case "html":
+		if err := reporter.PrintHTML(n.RouteIndicators, n.RouteMatches, fileName); err != nil {
+			n.Logger.Error("Error printing HTML", "error", err.Error())
+		}
+	case "openapi":
+		if err := reporter.PrintOpenAPI(n.RouteMatches, fileName); err != nil {
+			n.Logger.Error("Error printing OpenAPI", "error", err.Error())
+		}
+	case "egress":
+		if err := reporter.PrintEgress(n.RouteMatches, fileName); err != nil {
+			n.Logger.Error("Error printing egress inventory", "error", err.Error())
+		}
+	case "sql":
+		if err := reporter.PrintSQL(n.RouteMatches, fileName); err != nil {
+			n.Logger.Error("Error printing SQL inventory", "error", err.Error())
+		}
+	case "csv-matches", "tsv-matches":
+		comma := ','
+		if format == "tsv-matches" {
+			comma = '\t'
+		}
+		if err := reporter.WriteMatchesTable(n.RouteMatches, fileName, options.PathsFile, comma); err != nil {
+			n.Logger.Error("Error printing CSV", "error", err.Error())
+		}
+	case "neo4j":
+		if err := reporter.WriteNeo4j(n.RouteMatches, fileName); err != nil {
+			n.Logger.Error("Error writing Neo4j import files", "error", err.Error())
+		}
+	case "csv":
 		if err := reporter.WriteCSVFile(n.RouteMatches, fileName); err != nil {
 			n.Logger.Error("Error printing CSV", "error", err.Error())
 		}
-	} else {
+	default:
 		reporter.PrintResults(n.RouteMatches)
+		if n.SuppressedCount > 0 && !n.ShowSuppressed {
+			fmt.Println("Suppressed Results: ", n.SuppressedCount)
+		}
 	}
 }
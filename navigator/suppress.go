@@ -0,0 +1,263 @@
+package navigator
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/reporter"
+	"go/ast"
+	"golang.org/x/tools/go/analysis"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ignoreDirective marks a match as accepted when placed on the line of the call or on the line above it:
+//
+//	//wally:ignore <indicator-id>[,<indicator-id>...] [reason] [expires=YYYY-MM-DD]
+const ignoreDirective = "//wally:ignore"
+
+const baselineVersion = 1
+
+// Baseline holds matches accepted as existing risk, generated with --write-baseline
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is a single accepted match. Matches are found by MatchId, or by indicator, enclosing function and
+// route, or file and params, when their ID changed because the call moved. Reason and Expires can be edited, and
+// are kept when the baseline is written again
+type BaselineEntry struct {
+	MatchId    string            `json:"matchId"`
+	Indicator  string            `json:"indicator"`
+	Route      string            `json:"route,omitempty"`
+	Position   string            `json:"position"`
+	EnclosedBy string            `json:"enclosedBy,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Expires    string            `json:"expires,omitempty"`
+}
+
+type ignoreComment struct {
+	IndicatorIDs []string
+	Reason       string
+	Expires      string
+}
+
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("error parsing baseline %s: %v", path, err)
+	}
+	return &baseline, nil
+}
+
+// WriteBaseline writes every match that is not suppressed inline to path. Reasons and expiry dates of entries
+// already in the loaded baseline are kept
+func (n *Navigator) WriteBaseline(path string) error {
+	matches := append(append([]match.RouteMatch{}, n.RouteMatches...), n.SuppressedMatches...)
+	match.SortMatches(matches)
+	existing := n.Baseline.find(matches)
+
+	baseline := Baseline{Version: baselineVersion, Entries: []BaselineEntry{}}
+	for i, m := range matches {
+		if m.Suppression != nil && m.Suppression.Source == match.InlineSuppression {
+			continue
+		}
+		entry := newBaselineEntry(m)
+		if e, ok := existing[i]; ok {
+			entry.Reason = e.Reason
+			entry.Expires = e.Expires
+		}
+		baseline.Entries = append(baseline.Entries, entry)
+	}
+
+	out, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
+func newBaselineEntry(m match.RouteMatch) BaselineEntry {
+	var routes []string
+	for _, r := range m.HTTPRoutes() {
+		routes = append(routes, strings.TrimSpace(r.Method+" "+r.Path))
+	}
+	return BaselineEntry{
+		MatchId:    m.MatchId,
+		Indicator:  m.Indicator.Id,
+		Route:      strings.Join(routes, ", "),
+		Position:   fmt.Sprintf("%s:%d:%d", reporter.RelativeFilename(m.Pos.Filename), m.Pos.Line, m.Pos.Column),
+		EnclosedBy: m.EnclosedBy,
+		Params:     m.Params,
+	}
+}
+
+// key identifies an entry regardless of its position, as diff.Compare pairs matches whose ID changed: by indicator,
+// enclosing function and routes, or by indicator, enclosing function, file and params for matches without routes
+func (e BaselineEntry) key() string {
+	if e.Route != "" {
+		return strings.Join([]string{e.Indicator, e.EnclosedBy, e.Route}, "\x00")
+	}
+	file := e.Position
+	for range 2 {
+		if i := strings.LastIndex(file, ":"); i >= 0 {
+			file = file[:i]
+		}
+	}
+	var params []string
+	for k, v := range e.Params {
+		params = append(params, k+"="+v)
+	}
+	sort.Strings(params)
+	return strings.Join(append([]string{e.Indicator, e.EnclosedBy, file}, params...), "\x00")
+}
+
+// find returns the entries of the baseline found for matches, by index of the match. Entries are looked up by
+// match ID first, and the remaining ones by key, so that each entry is found for a single match
+func (b *Baseline) find(matches []match.RouteMatch) map[int]BaselineEntry {
+	found := make(map[int]BaselineEntry)
+	if b == nil {
+		return found
+	}
+
+	byID := make(map[string][]BaselineEntry)
+	for _, e := range b.Entries {
+		byID[e.MatchId] = append(byID[e.MatchId], e)
+	}
+	for i, m := range matches {
+		if entries := byID[m.MatchId]; len(entries) > 0 {
+			found[i] = entries[0]
+			byID[m.MatchId] = entries[1:]
+		}
+	}
+
+	byKey := make(map[string][]BaselineEntry)
+	for _, entries := range byID {
+		for _, e := range entries {
+			byKey[e.key()] = append(byKey[e.key()], e)
+		}
+	}
+	for i, m := range matches {
+		if _, ok := found[i]; ok {
+			continue
+		}
+		k := newBaselineEntry(m).key()
+		if entries := byKey[k]; len(entries) > 0 {
+			found[i] = entries[0]
+			byKey[k] = entries[1:]
+		}
+	}
+	return found
+}
+
+// collectIgnoreComments returns the wally:ignore comments of the files in the pass, by file name and line
+func collectIgnoreComments(pass *analysis.Pass) map[string]map[int]ignoreComment {
+	res := make(map[string]map[int]ignoreComment)
+	for _, file := range pass.Files {
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				ic, ok := parseIgnoreComment(c)
+				if !ok {
+					continue
+				}
+				pos := pass.Fset.Position(c.Pos())
+				if res[pos.Filename] == nil {
+					res[pos.Filename] = make(map[int]ignoreComment)
+				}
+				res[pos.Filename][pos.Line] = ic
+			}
+		}
+	}
+	return res
+}
+
+func parseIgnoreComment(c *ast.Comment) (ignoreComment, bool) {
+	if !strings.HasPrefix(c.Text, ignoreDirective) {
+		return ignoreComment{}, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(c.Text, ignoreDirective))
+	// Guard against directives such as //wally:ignored
+	if len(fields) == 0 || !strings.HasPrefix(c.Text, ignoreDirective+" ") {
+		return ignoreComment{}, false
+	}
+
+	ic := ignoreComment{IndicatorIDs: strings.Split(fields[0], ",")}
+	var reason []string
+	for _, f := range fields[1:] {
+		if strings.HasPrefix(f, "expires=") {
+			ic.Expires = strings.TrimPrefix(f, "expires=")
+			continue
+		}
+		reason = append(reason, f)
+	}
+	ic.Reason = strings.Join(reason, " ")
+	return ic, true
+}
+
+// inlineSuppression returns the suppression for a match of indicatorID on line if the line, or the line above it,
+// has a wally:ignore comment for the indicator
+func inlineSuppression(comments map[int]ignoreComment, line int, indicatorID string) *match.Suppression {
+	for _, l := range []int{line, line - 1} {
+		ic, ok := comments[l]
+		if !ok {
+			continue
+		}
+		for _, id := range ic.IndicatorIDs {
+			if id == indicatorID || id == "*" {
+				return &match.Suppression{
+					Source:  match.InlineSuppression,
+					Reason:  ic.Reason,
+					Expires: ic.Expires,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applySuppressions marks matches found in the baseline, drops expired suppressions and, unless ShowSuppressed
+// is set, removes suppressed matches from RouteMatches
+func (n *Navigator) applySuppressions() {
+	baselineEntries := n.Baseline.find(n.RouteMatches)
+
+	now := time.Now()
+	var results []match.RouteMatch
+	for i, m := range n.RouteMatches {
+		if m.Suppression == nil {
+			if e, ok := baselineEntries[i]; ok {
+				m.Suppression = &match.Suppression{
+					Source:  match.BaselineSuppression,
+					Reason:  e.Reason,
+					Expires: e.Expires,
+				}
+			}
+		}
+
+		if m.Suppression != nil && m.Suppression.Expired(now) {
+			n.Logger.Warn("Suppression expired", "match", m.Pos.String(), "source", m.Suppression.Source, "expires", m.Suppression.Expires)
+			m.Suppression = nil
+		}
+
+		if m.Suppression != nil {
+			n.SuppressedCount++
+			if !n.ShowSuppressed {
+				n.SuppressedMatches = append(n.SuppressedMatches, m)
+				continue
+			}
+		}
+		results = append(results, m)
+	}
+
+	if n.SuppressedCount > 0 {
+		n.Logger.Info("Suppressed matches", "count", n.SuppressedCount)
+	}
+	n.RouteMatches = results
+}
@@ -0,0 +1,62 @@
+package navigator
+
+import (
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestBaselineFind(t *testing.T) {
+	route := match.RouteMatch{
+		MatchId:    "moved-route",
+		Indicator:  indicator.Indicator{Id: "1", Params: []indicator.RouteParam{{Name: "pattern"}}},
+		Params:     map[string]string{"pattern": `"GET /users"`},
+		Pos:        token.Position{Filename: "api/routes.go", Line: 20, Column: 2},
+		EnclosedBy: "api.Register",
+	}
+	query := match.RouteMatch{
+		MatchId:    "moved-query",
+		Indicator:  indicator.Indicator{Id: "40"},
+		Params:     map[string]string{"query": `"SELECT * FROM users"`},
+		Pos:        token.Position{Filename: "store/users.go", Line: 12, Column: 9},
+		EnclosedBy: "store.List",
+	}
+	same := match.RouteMatch{
+		MatchId:    "same",
+		Indicator:  indicator.Indicator{Id: "40"},
+		Params:     map[string]string{"query": `"SELECT * FROM users"`},
+		Pos:        token.Position{Filename: "store/users.go", Line: 30, Column: 9},
+		EnclosedBy: "store.List",
+	}
+
+	entry := func(m match.RouteMatch, id string, line int, reason string) BaselineEntry {
+		m.MatchId = id
+		m.Pos.Line = line
+		e := newBaselineEntry(m)
+		e.Reason = reason
+		return e
+	}
+	baseline := &Baseline{Entries: []BaselineEntry{
+		entry(same, "same", 30, "by id"),
+		entry(route, "old-route", 10, "route"),
+		entry(query, "old-query", 5, "query"),
+	}}
+
+	got := baseline.find([]match.RouteMatch{route, query, same})
+	want := map[int]string{0: "route", 1: "query", 2: "by id"}
+	reasons := make(map[int]string)
+	for i, e := range got {
+		reasons[i] = e.Reason
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("find() reasons = %v, want %v", reasons, want)
+	}
+
+	// A changed query is a different match
+	query.Params = map[string]string{"query": `"DELETE FROM users"`}
+	if got := baseline.find([]match.RouteMatch{query}); len(got) != 0 {
+		t.Errorf("find() = %v for a changed query, want none", got)
+	}
+}
@@ -0,0 +1,15 @@
+package cli
+
+import (
+	"flag"
+	"time"
+)
+
+func Run() {
+	interval := flag.Duration("interval", time.Minute, "poll interval")
+	flag.Parse()
+
+	t := time.NewTicker(*interval)
+	for range t.C {
+	}
+}
@@ -0,0 +1,13 @@
+package queue
+
+import "time"
+
+func Start() {
+	go run()
+}
+
+func run() {
+	t := time.NewTicker(time.Minute)
+	for range t.C {
+	}
+}
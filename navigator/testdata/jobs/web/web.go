@@ -0,0 +1,15 @@
+package web
+
+import (
+	"net/http"
+	"time"
+)
+
+func slow(w http.ResponseWriter, r *http.Request) {
+	t := time.NewTicker(time.Second)
+	<-t.C
+}
+
+func Register() {
+	http.HandleFunc("/slow", slow)
+}
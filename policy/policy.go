@@ -0,0 +1,266 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"golang.org/x/tools/go/ssa"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Policy is a rule evaluated over every match it applies to. Path, Method, Indicators and Function select the
+// matches the policy applies to, and the remaining fields are the requirements those matches must meet
+type Policy struct {
+	Id          string `yaml:"id"`
+	Description string `yaml:"description"`
+
+	// Indicators limits the policy to matches of the given indicator IDs
+	Indicators []string `yaml:"indicators"`
+	// Function limits the policy to matches of a function, as <package>.<function> (i.e. os/exec.Command)
+	Function string `yaml:"function"`
+	// Path and Method are regular expressions matched against the HTTP routes resolved from the params of a match
+	Path   string `yaml:"path"`
+	Method string `yaml:"method"`
+
+	// Deny reports every match the policy applies to
+	Deny bool `yaml:"deny"`
+	// Params maps param names to regular expressions that every value resolved for the param must match
+	Params map[string]string `yaml:"params"`
+	// MustContain lists functions that every call path must go through
+	MustContain []string `yaml:"mustContain"`
+	// AnyPathContains lists functions that at least one call path must go through
+	AnyPathContains []string `yaml:"anyPathContains"`
+	// MustNotContain lists functions that no call path may go through
+	MustNotContain []string `yaml:"mustNotContain"`
+	// Recoverable, when set, requires every call path to be recoverable (true) or not recoverable (false)
+	Recoverable *bool `yaml:"recoverable"`
+
+	pathRe   *regexp.Regexp
+	methodRe *regexp.Regexp
+	paramRes map[string]*regexp.Regexp
+}
+
+// Compile validates policies and compiles their regular expressions. It must be called before Evaluate
+func Compile(policies []Policy) error {
+	for i := range policies {
+		p := &policies[i]
+		if p.Id == "" {
+			p.Id = fmt.Sprintf("policy-%d", i+1)
+		}
+
+		var err error
+		if p.Path != "" {
+			if p.pathRe, err = regexp.Compile(p.Path); err != nil {
+				return fmt.Errorf("policy %s: invalid path regex: %v", p.Id, err)
+			}
+		}
+		if p.Method != "" {
+			if p.methodRe, err = regexp.Compile(p.Method); err != nil {
+				return fmt.Errorf("policy %s: invalid method regex: %v", p.Id, err)
+			}
+		}
+		p.paramRes = make(map[string]*regexp.Regexp)
+		for name, expr := range p.Params {
+			if p.paramRes[name], err = regexp.Compile(expr); err != nil {
+				return fmt.Errorf("policy %s: invalid regex for param %s: %v", p.Id, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// NeedsPaths reports whether evaluating the policy requires call paths, which are only solved with SSA
+func (p *Policy) NeedsPaths() bool {
+	return len(p.MustContain) > 0 || len(p.AnyPathContains) > 0 || len(p.MustNotContain) > 0 || p.Recoverable != nil
+}
+
+// Evaluate checks every match against every policy that applies to it, sets the Violations of each match, and
+// returns the total number of violations of matches that are not suppressed. Call path rules are only evaluated when
+// pathsSolved is set, as paths are only solved with SSA
+func Evaluate(policies []Policy, matches []match.RouteMatch, pathsSolved bool) int {
+	total := 0
+	for i := range matches {
+		m := &matches[i]
+		m.Violations = nil
+		for x := range policies {
+			p := &policies[x]
+			if !p.appliesTo(m) {
+				continue
+			}
+			for _, msg := range p.check(m, pathsSolved) {
+				m.Violations = append(m.Violations, match.PolicyViolation{
+					PolicyId:    p.Id,
+					Description: p.Description,
+					Message:     msg,
+				})
+			}
+		}
+		if m.Suppression == nil {
+			total += len(m.Violations)
+		}
+	}
+	return total
+}
+
+func (p *Policy) appliesTo(m *match.RouteMatch) bool {
+	if len(p.Indicators) > 0 && !contains(p.Indicators, m.Indicator.Id) {
+		return false
+	}
+	if p.Function != "" && p.Function != m.Indicator.Package+"."+m.Indicator.Function {
+		return false
+	}
+	if p.pathRe == nil && p.methodRe == nil {
+		return true
+	}
+	// A match applies if any of its routes matches both the path and method expressions
+	for _, r := range m.HTTPRoutes() {
+		if p.pathRe != nil && !p.pathRe.MatchString(r.Path) {
+			continue
+		}
+		if p.methodRe != nil && !p.methodRe.MatchString(r.Method) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (p *Policy) check(m *match.RouteMatch, pathsSolved bool) []string {
+	var violations []string
+	if p.Deny {
+		violations = append(violations, "match is not allowed by policy")
+	}
+
+	// Every value a param resolved to (i.e. both values of "/a || /b") must match
+	for _, name := range sortedKeys(p.paramRes) {
+		values := match.ParamValues(m.Params[name])
+		if len(values) == 0 {
+			violations = append(violations, fmt.Sprintf("param %s could not be resolved", name))
+		}
+		for _, v := range values {
+			if !p.paramRes[name].MatchString(v) {
+				violations = append(violations, fmt.Sprintf("param %s (%s) does not match %s", name, v, p.Params[name]))
+			}
+		}
+	}
+
+	if !p.NeedsPaths() || !pathsSolved {
+		return violations
+	}
+
+	var paths []*match.CallPath
+	if m.SSA != nil && m.SSA.CallPaths != nil {
+		paths = m.SSA.CallPaths.Paths
+	}
+	// Rules on every path would otherwise pass for matches with no paths, i.e. when the enclosing function is unknown
+	if len(paths) == 0 && (len(p.MustContain) > 0 || p.Recoverable != nil) {
+		violations = append(violations, "no call paths to check mustContain or recoverable against")
+	}
+	for _, fn := range p.MustContain {
+		for i, path := range paths {
+			if !pathContains(m, path, fn) {
+				violations = append(violations, fmt.Sprintf("path %d does not go through %s", i+1, fn))
+			}
+		}
+	}
+	for _, fn := range p.AnyPathContains {
+		found := false
+		for _, path := range paths {
+			if pathContains(m, path, fn) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("no path goes through %s", fn))
+		}
+	}
+	for _, fn := range p.MustNotContain {
+		for i, path := range paths {
+			if pathContains(m, path, fn) {
+				violations = append(violations, fmt.Sprintf("path %d goes through %s", i+1, fn))
+			}
+		}
+	}
+	if p.Recoverable != nil {
+		for i, path := range paths {
+			if isRecoverable(path) != *p.Recoverable {
+				violations = append(violations, fmt.Sprintf("path %d recoverable is %t, expected %t", i+1, !*p.Recoverable, *p.Recoverable))
+			}
+		}
+	}
+	return violations
+}
+
+// pathContains reports whether a function is part of the path, including the function enclosing the match and the
+// middleware wrapping its handler, as in r.Handle("/admin", auth.RequireAdmin(h))
+func pathContains(m *match.RouteMatch, path *match.CallPath, name string) bool {
+	if m.SSA.EnclosedByFunc != nil && funcMatches(m.SSA.EnclosedByFunc, name) {
+		return true
+	}
+	for _, mw := range m.Middleware {
+		if mw.Func != nil && funcMatches(mw.Func, name) || mw.Func == nil && nameMatches(mw.Name, name) {
+			return true
+		}
+	}
+	for _, a := range m.Auth {
+		if nameMatches(a, name) {
+			return true
+		}
+	}
+	for _, node := range path.Nodes {
+		if node.Caller != nil && node.Caller.Func != nil && funcMatches(node.Caller.Func, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcMatches compares a function to a name given either as the full name of the function
+// (github.com/org/repo/auth.RequireAdmin), a suffix of it after a slash (auth.RequireAdmin),
+// or its package name followed by its name
+func funcMatches(fn *ssa.Function, name string) bool {
+	if nameMatches(fn.String(), name) {
+		return true
+	}
+	if fn.Pkg != nil && fn.Pkg.Pkg.Name()+"."+fn.RelString(fn.Pkg.Pkg) == name {
+		return true
+	}
+	return false
+}
+
+// nameMatches compares the full name of a function to a name given as the full name or a suffix of it after a slash
+func nameMatches(full, name string) bool {
+	return full == name || strings.HasSuffix(full, "/"+name)
+}
+
+func isRecoverable(path *match.CallPath) bool {
+	if path.Recoverable {
+		return true
+	}
+	for _, node := range path.Nodes {
+		if node.IsRecoverable() || strings.Contains(node.NodeString, "(recoverable)") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]*regexp.Regexp) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
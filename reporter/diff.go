@@ -0,0 +1,146 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/diff"
+	"strings"
+)
+
+// PrintDiff writes the differences between two analysis results as text, json or markdown
+func PrintDiff(report *diff.Report, format string, filename string) error {
+	switch format {
+	case "", "text":
+		return writeOutput([]byte(buildDiffText(report)), filename)
+	case "json":
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutput(append(out, '\n'), filename)
+	case "markdown":
+		return writeOutput([]byte(buildDiffMarkdown(report)), filename)
+	default:
+		return fmt.Errorf("unsupported diff format %q. Supported: text, json, markdown", format)
+	}
+}
+
+func buildDiffText(report *diff.Report) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Added routes: %d, removed routes: %d, changed routes: %d, unchanged routes: %d\n",
+		len(report.Added), len(report.Removed), len(report.Changed), report.Unchanged))
+
+	for _, r := range report.Added {
+		sb.WriteString(fmt.Sprintf("\n+ %s\n", r.Route))
+		writeDiffRouteText(&sb, r)
+	}
+	for _, r := range report.Removed {
+		sb.WriteString(fmt.Sprintf("\n- %s\n", r.Route))
+		writeDiffRouteText(&sb, r)
+	}
+	for _, c := range report.Changed {
+		sb.WriteString(fmt.Sprintf("\n~ %s\n", c.Route.Route))
+		writeDiffRouteText(&sb, c.Route)
+		if c.OldMatchId != "" {
+			sb.WriteString(fmt.Sprintf("\tPrevious ID: %s\n", c.OldMatchId))
+		}
+		for _, p := range c.ParamChanges {
+			sb.WriteString(fmt.Sprintf("\tParam %s: %q -> %q\n", p.Name, p.Old, p.New))
+		}
+		for _, p := range c.NewPaths {
+			sb.WriteString(fmt.Sprintf("\t+ Path: %s\n", strings.Join(p, " --> ")))
+		}
+		for _, p := range c.RemovedPaths {
+			sb.WriteString(fmt.Sprintf("\t- Path: %s\n", strings.Join(p, " --> ")))
+		}
+		for _, rc := range c.RecoverabilityChanges {
+			sb.WriteString(fmt.Sprintf("\tRecoverable %t -> %t: %s\n", rc.Old, rc.New, strings.Join(rc.Path, " --> ")))
+		}
+	}
+	return sb.String()
+}
+
+func writeDiffRouteText(sb *strings.Builder, r diff.Route) {
+	sb.WriteString(fmt.Sprintf("\tID: %s\n", r.MatchId))
+	sb.WriteString(fmt.Sprintf("\tIndicator: %s\n", r.Indicator))
+	sb.WriteString(fmt.Sprintf("\tPosition: %s\n", relativePos(r.Pos)))
+	if r.EnclosedBy != "" {
+		sb.WriteString(fmt.Sprintf("\tEnclosed by: %s\n", r.EnclosedBy))
+	}
+	if r.Handler != "" {
+		sb.WriteString(fmt.Sprintf("\tHandler: %s\n", r.Handler))
+	}
+}
+
+func buildDiffMarkdown(report *diff.Report) string {
+	var sb strings.Builder
+	sb.WriteString("## Wally diff\n\n")
+	if report.Empty() {
+		sb.WriteString("No changes to routes or call paths\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Added | Removed | Changed | Unchanged |\n")
+	sb.WriteString("|---:|---:|---:|---:|\n")
+	sb.WriteString(fmt.Sprintf("| %d | %d | %d | %d |\n", len(report.Added), len(report.Removed), len(report.Changed), report.Unchanged))
+
+	writeRoutes := func(title string, routes []diff.Route) {
+		if len(routes) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", title))
+		sb.WriteString("| Route | Enclosed by | Position | ID |\n")
+		sb.WriteString("|---|---|---|---|\n")
+		for _, r := range routes {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				diffCodeCell(r.Route), diffCodeCell(r.EnclosedBy), diffCodeCell(relativePos(r.Pos)), diffCodeCell(r.MatchId)))
+		}
+	}
+	writeRoutes("Added routes", report.Added)
+	writeRoutes("Removed routes", report.Removed)
+
+	if len(report.Changed) > 0 {
+		sb.WriteString("\n### Changed routes\n\n")
+	}
+	for _, c := range report.Changed {
+		sb.WriteString(fmt.Sprintf("<details>\n<summary><code>%s</code> in <code>%s</code></summary>\n\n",
+			htmlEscape(c.Route.Route), htmlEscape(c.EnclosedBy)))
+		for _, p := range c.ParamChanges {
+			sb.WriteString(fmt.Sprintf("- Param %s: %s → %s\n", diffCode(p.Name), diffCode(p.Old), diffCode(p.New)))
+		}
+		for _, p := range c.NewPaths {
+			sb.WriteString(fmt.Sprintf("- New path: %s\n", diffCode(strings.Join(p, " → "))))
+		}
+		for _, p := range c.RemovedPaths {
+			sb.WriteString(fmt.Sprintf("- Removed path: %s\n", diffCode(strings.Join(p, " → "))))
+		}
+		for _, rc := range c.RecoverabilityChanges {
+			sb.WriteString(fmt.Sprintf("- Recoverable %t → %t: %s\n", rc.Old, rc.New, diffCode(strings.Join(rc.Path, " → "))))
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+	return sb.String()
+}
+
+// relativePos makes the file in a position formatted as file:line:column relative to the working directory
+func relativePos(pos string) string {
+	parts := strings.SplitN(pos, ":", 2)
+	if len(parts) != 2 {
+		return pos
+	}
+	return RelativeFilename(parts[0]) + ":" + parts[1]
+}
+
+func diffCode(s string) string {
+	if s == "" {
+		return "_none_"
+	}
+	return markdownCode(s)
+}
+
+func diffCodeCell(s string) string {
+	if s == "" {
+		return "_none_"
+	}
+	return markdownCodeCell(s)
+}
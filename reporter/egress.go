@@ -0,0 +1,155 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"sort"
+)
+
+// Kinds of outbound calls whose targets are addresses, as opposed to SQL drivers or Kafka topics
+var addressEgress = map[string]bool{
+	indicator.HTTPEgress:  true,
+	indicator.GRPCEgress:  true,
+	indicator.RedisEgress: true,
+}
+
+type egressInventory struct {
+	Modules []egressModule `json:"modules"`
+}
+
+type egressModule struct {
+	Module string       `json:"module"`
+	Hosts  []string     `json:"hosts"`
+	Calls  []egressCall `json:"calls"`
+}
+
+type egressCall struct {
+	MatchID    string            `json:"matchId"`
+	Kind       string            `json:"kind"`
+	Function   string            `json:"function"`
+	Targets    []string          `json:"targets,omitempty"`
+	Hosts      []string          `json:"hosts,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Position   string            `json:"position"`
+	EnclosedBy string            `json:"enclosedBy"`
+	// ReachedFrom is omitted when call paths were not solved
+	ReachedFrom *[]egressRoute `json:"reachedFrom,omitempty"`
+}
+
+type egressRoute struct {
+	MatchID string   `json:"matchId"`
+	Kind    string   `json:"kind"`
+	Routes  []string `json:"routes,omitempty"`
+	Command string   `json:"command,omitempty"`
+	Handler string   `json:"handler,omitempty"`
+}
+
+// PrintEgress writes the outbound calls among matches as JSON, grouped by the module making them, along with the
+// routes reaching each call when call paths were solved
+func PrintEgress(matches []match.RouteMatch, filename string) error {
+	out, err := json.MarshalIndent(buildEgressInventory(matches), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(out, filename)
+}
+
+func buildEgressInventory(matches []match.RouteMatch) egressInventory {
+	byID := make(map[string]match.RouteMatch, len(matches))
+	for _, m := range matches {
+		byID[m.MatchId] = m
+	}
+
+	inventory := egressInventory{Modules: []egressModule{}}
+	modules := make(map[string]int)
+	for _, m := range matches {
+		if m.Indicator.Egress == "" {
+			continue
+		}
+		i, ok := modules[m.Module]
+		if !ok {
+			i = len(inventory.Modules)
+			modules[m.Module] = i
+			inventory.Modules = append(inventory.Modules, egressModule{Module: m.Module, Hosts: []string{}})
+		}
+		mod := &inventory.Modules[i]
+
+		call := egressCall{
+			MatchID:    m.MatchId,
+			Kind:       m.Indicator.Egress,
+			Function:   m.Indicator.Package + "." + m.Indicator.Function,
+			Targets:    m.Targets(),
+			Params:     m.Params,
+			Position:   fmt.Sprintf("%s:%d", RelativeFilename(m.Pos.Filename), m.Pos.Line),
+			EnclosedBy: m.EnclosedBy,
+		}
+		if m.Indicator.Struct != "" {
+			call.Function = m.Indicator.Package + "." + m.Indicator.Struct
+		}
+		for _, target := range call.Targets {
+			if !addressEgress[call.Kind] {
+				break
+			}
+			if host := match.TargetHost(target); host != "" {
+				call.Hosts = appendUnique(call.Hosts, host)
+				mod.Hosts = appendUnique(mod.Hosts, host)
+			}
+		}
+		if m.ReachedFrom != nil {
+			routes := []egressRoute{}
+			for _, id := range m.ReachedFrom {
+				if route, ok := byID[id]; ok {
+					routes = append(routes, egressRouteOf(route))
+				}
+			}
+			call.ReachedFrom = &routes
+		}
+		mod.Calls = append(mod.Calls, call)
+	}
+
+	for i := range inventory.Modules {
+		sort.Strings(inventory.Modules[i].Hosts)
+	}
+	sort.Slice(inventory.Modules, func(i, j int) bool {
+		return inventory.Modules[i].Module < inventory.Modules[j].Module
+	})
+	return inventory
+}
+
+// egressRouteOf describes a route reaching an outbound call by its HTTP routes, or by its resolved params for
+// other kinds of routes, as in the method of an RPC
+func egressRouteOf(m match.RouteMatch) egressRoute {
+	route := egressRoute{
+		MatchID: m.MatchId,
+		Kind:    m.Indicator.Kind(),
+		Command: m.Command,
+		Handler: m.Handler,
+	}
+	for _, r := range m.HTTPRoutes() {
+		route.Routes = append(route.Routes, httpRouteString(r))
+	}
+	if len(route.Routes) == 0 {
+		for _, k := range sortedParamKeys(m.Params) {
+			route.Routes = append(route.Routes, match.ParamValues(m.Params[k])...)
+		}
+	}
+	return route
+}
+
+func httpRouteString(r match.HTTPRoute) string {
+	if r.Method == "" {
+		return r.Path
+	}
+	return r.Method + " " + r.Path
+}
+
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
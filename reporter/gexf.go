@@ -0,0 +1,128 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"github.com/hex0punk/wally/match"
+	"strconv"
+	"strings"
+)
+
+type gexfDoc struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    int            `xml:"weight,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+var gexfNodeAttributes = []gexfAttribute{
+	{ID: "package", Title: "package", Type: "string"},
+	{ID: "function", Title: "function", Type: "string"},
+	{ID: "file", Title: "file", Type: "string"},
+	{ID: "line", Title: "line", Type: "integer"},
+	{ID: "recoverable", Title: "recoverable", Type: "boolean"},
+	{ID: "is_match", Title: "is_match", Type: "boolean"},
+	{ID: "is_root", Title: "is_root", Type: "boolean"},
+}
+
+var gexfEdgeAttributes = []gexfAttribute{
+	{ID: "kind", Title: "kind", Type: "string"},
+	{ID: "match_ids", Title: "match_ids", Type: "string"},
+}
+
+// WriteGEXF writes the union graph of all call paths in GEXF 1.3 format, for tools such as Gephi.
+// Edge weights are the number of matches with a path using the edge
+func WriteGEXF(matches []match.RouteMatch, path string) error {
+	g := buildUnionGraph(matches)
+
+	doc := gexfDoc{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Meta: gexfMeta{
+			Creator:     "wally",
+			Description: "Union of call paths to wally matches",
+		},
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: gexfNodeAttributes},
+				{Class: "edge", Attributes: gexfEdgeAttributes},
+			},
+		},
+	}
+
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    n.ID,
+			Label: n.Label,
+			AttValues: []gexfAttValue{
+				{For: "package", Value: n.Package},
+				{For: "function", Value: n.Function},
+				{For: "file", Value: n.File},
+				{For: "line", Value: strconv.Itoa(n.Line)},
+				{For: "recoverable", Value: strconv.FormatBool(n.Recoverable)},
+				{For: "is_match", Value: strconv.FormatBool(n.IsMatch)},
+				{For: "is_root", Value: strconv.FormatBool(n.IsRoot)},
+			},
+		})
+	}
+
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     e.ID,
+			Source: e.From.ID,
+			Target: e.To.ID,
+			Weight: len(e.MatchIDs),
+			AttValues: []gexfAttValue{
+				{For: "kind", Value: e.Kind},
+				{For: "match_ids", Value: strings.Join(e.MatchIDs, ",")},
+			},
+		})
+	}
+
+	return writeXMLFile(path, doc)
+}
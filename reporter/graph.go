@@ -0,0 +1,244 @@
+package reporter
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type nodeKind int
+
+// Node kinds mirror the node colors used by wally server (see assets/*-node.svg)
+const (
+	pathNode nodeKind = iota
+	rootNode
+	dualNode
+	findingNode
+	routeNode
+)
+
+type nodeStyle struct {
+	Class     string
+	FillColor string
+	FontColor string
+	Shape     string
+}
+
+var nodeStyles = map[nodeKind]nodeStyle{
+	pathNode:    {Class: "path", FillColor: "#4287f5", FontColor: "white", Shape: "box"},
+	rootNode:    {Class: "root", FillColor: "purple", FontColor: "white", Shape: "box"},
+	dualNode:    {Class: "dual", FillColor: "#FFCE85", FontColor: "black", Shape: "box"},
+	findingNode: {Class: "finding", FillColor: "#984040", FontColor: "white", Shape: "box"},
+	routeNode:   {Class: "route", FillColor: "#984040", FontColor: "white", Shape: "hexagon"},
+}
+
+const recoverableColor = "#1a7f37"
+
+type graphNode struct {
+	ID          string
+	Label       string
+	Kind        nodeKind
+	Recoverable bool
+
+	isRoot         bool
+	isIntermediate bool
+}
+
+type graphEdge struct {
+	From string
+	To   string
+}
+
+// matchGraph holds the nodes and deduplicated edges of all call paths of a single match
+type matchGraph struct {
+	ID    string
+	Title string
+	Nodes []*graphNode
+	Edges []graphEdge
+}
+
+// GenerateGraph writes a graph of the call paths of all matches. The format is chosen based on the extension of path:
+// .dot or .gv for DOT, .mmd or .mermaid for Mermaid flowcharts, .graphml and .gexf for the union graph of all paths
+// with node and edge attributes, and .png or .xdot for files rendered with Graphviz
+func GenerateGraph(matches []match.RouteMatch, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return writeGraphFile(path, buildDOT(buildMatchGraphs(matches)))
+	case ".mmd", ".mermaid":
+		return writeGraphFile(path, buildMermaid(buildMatchGraphs(matches)))
+	case ".graphml":
+		return WriteGraphML(matches, path)
+	case ".gexf":
+		return WriteGEXF(matches, path)
+	case ".png", ".xdot":
+		return renderGraphviz(buildMatchGraphs(matches), path)
+	default:
+		return fmt.Errorf("unsupported graph file extension %q. Supported: .dot, .gv, .mmd, .mermaid, .graphml, .gexf, .png, .xdot", filepath.Ext(path))
+	}
+}
+
+func writeGraphFile(path string, content string) error {
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func buildMatchGraphs(matches []match.RouteMatch) []matchGraph {
+	var graphs []matchGraph
+	for i, m := range matches {
+		if m.SSA == nil || m.SSA.CallPaths == nil || len(m.SSA.CallPaths.Paths) == 0 {
+			continue
+		}
+
+		mg := matchGraph{
+			ID:    fmt.Sprintf("m%d", i),
+			Title: graphTitle(m),
+		}
+		nodes := make(map[string]*graphNode)
+		edges := make(map[graphEdge]bool)
+
+		getNode := func(key, label string) *graphNode {
+			if n, ok := nodes[key]; ok {
+				return n
+			}
+			n := &graphNode{ID: fmt.Sprintf("%s_n%d", mg.ID, len(mg.Nodes)), Label: label}
+			nodes[key] = n
+			mg.Nodes = append(mg.Nodes, n)
+			return n
+		}
+		addEdge := func(from, to *graphNode) {
+			e := graphEdge{From: from.ID, To: to.ID}
+			if !edges[e] {
+				edges[e] = true
+				mg.Edges = append(mg.Edges, e)
+			}
+		}
+
+		targetLabel := m.SSA.TargetPos
+		if targetLabel == "" {
+			targetLabel = fmt.Sprintf("%s.[%s] %s", m.Indicator.Package, m.Indicator.Function, m.Pos.String())
+		}
+		target := getNode("target:"+targetLabel, targetLabel)
+
+		for _, path := range m.SSA.CallPaths.Paths {
+			var prev *graphNode
+			// Nodes are stored from the match upwards, so we walk them backwards to start at the root of the path
+			for x := len(path.Nodes) - 1; x >= 0; x-- {
+				wn := path.Nodes[x]
+				n := getNode(wn.NodeString, wn.NodeString)
+				n.Recoverable = n.Recoverable || wn.IsRecoverable() || strings.Contains(wn.NodeString, "(recoverable)")
+				if prev == nil {
+					n.isRoot = true
+				} else {
+					n.isIntermediate = true
+					addEdge(prev, n)
+				}
+				prev = n
+			}
+			if prev != nil {
+				addEdge(prev, target)
+			}
+		}
+
+		for _, n := range mg.Nodes {
+			switch {
+			case n == target && len(m.HTTPRoutes()) > 0:
+				n.Kind = routeNode
+			case n == target:
+				n.Kind = findingNode
+			case n.isRoot && n.isIntermediate:
+				n.Kind = dualNode
+			case n.isRoot:
+				n.Kind = rootNode
+			default:
+				n.Kind = pathNode
+			}
+		}
+
+		graphs = append(graphs, mg)
+	}
+	return graphs
+}
+
+func graphTitle(m match.RouteMatch) string {
+	var routes []string
+	for _, r := range m.HTTPRoutes() {
+		routes = append(routes, strings.TrimSpace(r.Method+" "+r.Path))
+	}
+	if len(routes) > 0 {
+		return strings.Join(routes, ", ")
+	}
+	return fmt.Sprintf("%s.%s %s:%d", m.Indicator.Package, m.Indicator.Function, RelativeFilename(m.Pos.Filename), m.Pos.Line)
+}
+
+func buildDOT(graphs []matchGraph) string {
+	var sb strings.Builder
+	sb.WriteString("digraph wally {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [style=filled, fontname=\"Helvetica\"];\n")
+	sb.WriteString("  edge [color=\"#8C8C8C\"];\n")
+
+	for i, mg := range graphs {
+		sb.WriteString(fmt.Sprintf("\n  subgraph cluster_%d {\n", i))
+		sb.WriteString(fmt.Sprintf("    label=%s;\n", dotQuote(mg.Title)))
+		for _, n := range mg.Nodes {
+			style := nodeStyles[n.Kind]
+			attrs := fmt.Sprintf("label=%s, shape=%s, fillcolor=%s, fontcolor=%s",
+				dotQuote(n.Label), style.Shape, dotQuote(style.FillColor), dotQuote(style.FontColor))
+			if n.Recoverable {
+				attrs += fmt.Sprintf(", color=%s, penwidth=3, peripheries=2", dotQuote(recoverableColor))
+			}
+			sb.WriteString(fmt.Sprintf("    %s [%s];\n", n.ID, attrs))
+		}
+		for _, e := range mg.Edges {
+			sb.WriteString(fmt.Sprintf("    %s -> %s;\n", e.From, e.To))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s) + "\""
+}
+
+func buildMermaid(graphs []matchGraph) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	var recoverable []string
+	for _, mg := range graphs {
+		sb.WriteString(fmt.Sprintf("  subgraph %s[%s]\n", mg.ID, mermaidQuote(mg.Title)))
+		for _, n := range mg.Nodes {
+			open, closing := "[", "]"
+			if nodeStyles[n.Kind].Shape == "hexagon" {
+				open, closing = "{{", "}}"
+			}
+			sb.WriteString(fmt.Sprintf("    %s%s%s%s:::%s\n", n.ID, open, mermaidQuote(n.Label), closing, nodeStyles[n.Kind].Class))
+			if n.Recoverable {
+				recoverable = append(recoverable, n.ID)
+			}
+		}
+		for _, e := range mg.Edges {
+			sb.WriteString(fmt.Sprintf("    %s --> %s\n", e.From, e.To))
+		}
+		sb.WriteString("  end\n")
+	}
+
+	for _, kind := range []nodeKind{rootNode, pathNode, dualNode, findingNode, routeNode} {
+		style := nodeStyles[kind]
+		sb.WriteString(fmt.Sprintf("  classDef %s fill:%s,color:%s\n", style.Class, style.FillColor, style.FontColor))
+	}
+	sb.WriteString(fmt.Sprintf("  classDef recoverable stroke:%s,stroke-width:4px\n", recoverableColor))
+	if len(recoverable) > 0 {
+		sb.WriteString(fmt.Sprintf("  class %s recoverable\n", strings.Join(recoverable, ",")))
+	}
+	return sb.String()
+}
+
+// mermaidQuote quotes labels so that characters such as brackets in node strings are not parsed as shapes
+func mermaidQuote(s string) string {
+	return "\"" + strings.ReplaceAll(s, "\"", "#quot;") + "\""
+}
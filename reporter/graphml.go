@@ -0,0 +1,111 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"github.com/hex0punk/wally/match"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type graphMLDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "package", For: "node", AttrName: "package", AttrType: "string"},
+	{ID: "function", For: "node", AttrName: "function", AttrType: "string"},
+	{ID: "file", For: "node", AttrName: "file", AttrType: "string"},
+	{ID: "line", For: "node", AttrName: "line", AttrType: "int"},
+	{ID: "recoverable", For: "node", AttrName: "recoverable", AttrType: "boolean"},
+	{ID: "is_match", For: "node", AttrName: "is_match", AttrType: "boolean"},
+	{ID: "is_root", For: "node", AttrName: "is_root", AttrType: "boolean"},
+	{ID: "kind", For: "edge", AttrName: "kind", AttrType: "string"},
+	{ID: "match_ids", For: "edge", AttrName: "match_ids", AttrType: "string"},
+	{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+}
+
+// WriteGraphML writes the union graph of all call paths in GraphML format, for tools such as yEd or Gephi
+func WriteGraphML(matches []match.RouteMatch, path string) error {
+	g := buildUnionGraph(matches)
+
+	doc := graphMLDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "wally", EdgeDefault: "directed"},
+	}
+
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: n.Label},
+				{Key: "package", Value: n.Package},
+				{Key: "function", Value: n.Function},
+				{Key: "file", Value: n.File},
+				{Key: "line", Value: strconv.Itoa(n.Line)},
+				{Key: "recoverable", Value: strconv.FormatBool(n.Recoverable)},
+				{Key: "is_match", Value: strconv.FormatBool(n.IsMatch)},
+				{Key: "is_root", Value: strconv.FormatBool(n.IsRoot)},
+			},
+		})
+	}
+
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     e.ID,
+			Source: e.From.ID,
+			Target: e.To.ID,
+			Data: []graphMLData{
+				{Key: "kind", Value: e.Kind},
+				{Key: "match_ids", Value: strings.Join(e.MatchIDs, ",")},
+				{Key: "weight", Value: strconv.Itoa(len(e.MatchIDs))},
+			},
+		})
+	}
+
+	return writeXMLFile(path, doc)
+}
+
+func writeXMLFile(path string, doc interface{}) error {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(out, '\n')...), 0644)
+}
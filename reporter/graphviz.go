@@ -0,0 +1,59 @@
+//go:build cgo
+
+package reporter
+
+import (
+	"fmt"
+	"github.com/goccy/go-graphviz"
+	"github.com/goccy/go-graphviz/cgraph"
+	"path/filepath"
+	"strings"
+)
+
+// renderGraphviz renders graphs to a PNG or XDOT file using Graphviz, which requires cgo
+func renderGraphviz(graphs []matchGraph, path string) error {
+	g := graphviz.New()
+	defer g.Close()
+
+	graph, err := g.Graph()
+	if err != nil {
+		return err
+	}
+	defer graph.Close()
+	graph.SetRankDir(cgraph.LRRank)
+
+	for i, mg := range graphs {
+		sub := graph.SubGraph(fmt.Sprintf("cluster_%d", i), 1)
+		sub.SetLabel(mg.Title)
+
+		nodes := make(map[string]*cgraph.Node)
+		for _, n := range mg.Nodes {
+			style := nodeStyles[n.Kind]
+			gn, err := sub.CreateNode(n.ID)
+			if err != nil {
+				return err
+			}
+			gn.SetLabel(n.Label).
+				SetShape(cgraph.Shape(style.Shape)).
+				SetStyle(cgraph.FilledNodeStyle).
+				SetFillColor(style.FillColor).
+				SetFontColor(style.FontColor)
+			if n.Recoverable {
+				gn.SetColor(recoverableColor).SetPenWidth(3).SetPeripheries(2)
+			}
+			nodes[n.ID] = gn
+		}
+
+		for i, e := range mg.Edges {
+			if _, err := sub.CreateEdge(fmt.Sprintf("%s_e%d", mg.ID, i), nodes[e.From], nodes[e.To]); err != nil {
+				return err
+			}
+		}
+	}
+
+	format := graphviz.PNG
+	if strings.ToLower(filepath.Ext(path)) == ".xdot" {
+		format = graphviz.XDOT
+	}
+	return g.RenderFilename(graph, format, path)
+}
@@ -0,0 +1,10 @@
+//go:build !cgo
+
+package reporter
+
+import "errors"
+
+// renderGraphviz is not available without cgo. Use a .dot or .mmd graph file instead
+func renderGraphviz(graphs []matchGraph, path string) error {
+	return errors.New("PNG and XDOT graphs require wally to be built with cgo. Use a .dot or .mmd file instead")
+}
@@ -0,0 +1,170 @@
+package reporter
+
+import (
+	"bytes"
+	_ "embed"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"html/template"
+	"time"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+type htmlReport struct {
+	Generated  string
+	Stats      htmlStats
+	Indicators []htmlIndicator
+	Matches    []htmlMatch
+}
+
+type htmlStats struct {
+	Matches          int
+	Indicators       int
+	Modules          int
+	Paths            int
+	RecoverablePaths int
+	LimitedMatches   int
+	Violations       int
+}
+
+type htmlIndicator struct {
+	indicator.Indicator
+	Matches int
+}
+
+type htmlMatch struct {
+	ID          string
+	IndicatorID string
+	Function    string
+	Module      string
+	Params      []htmlParam
+	Handler     string
+	EnclosedBy  string
+	Position    string
+	PathLimited bool
+	Recoverable bool
+	Violations  []string
+	Paths       []htmlPath
+}
+
+type htmlParam struct {
+	Name  string
+	Value string
+}
+
+type htmlPath struct {
+	Index         int
+	Nodes         []string
+	Target        string
+	NodeLimited   bool
+	FilterLimited bool
+	Recoverable   bool
+}
+
+// PrintHTML writes a single, self-contained HTML report with summary statistics,
+// an indicator legend, and a sortable table of matches with their call paths
+func PrintHTML(indicators []indicator.Indicator, matches []match.RouteMatch, filename string) error {
+	tmpl, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, buildHTMLReport(indicators, matches)); err != nil {
+		return err
+	}
+
+	return writeOutput(buf.Bytes(), filename)
+}
+
+func buildHTMLReport(indicators []indicator.Indicator, matches []match.RouteMatch) htmlReport {
+	report := htmlReport{
+		Generated: time.Now().Format(time.RFC1123),
+	}
+
+	matchesPerIndicator := make(map[string]int)
+	modules := make(map[string]bool)
+	for _, m := range matches {
+		matchesPerIndicator[m.Indicator.Id]++
+		if m.Module != "" {
+			modules[m.Module] = true
+		}
+		hm := newHTMLMatch(m)
+		report.Stats.Paths += len(hm.Paths)
+		for _, p := range hm.Paths {
+			if p.Recoverable {
+				report.Stats.RecoverablePaths++
+			}
+		}
+		if isLimited(m) {
+			report.Stats.LimitedMatches++
+		}
+		report.Stats.Violations += len(m.Violations)
+		report.Matches = append(report.Matches, hm)
+	}
+
+	for _, ind := range indicators {
+		report.Indicators = append(report.Indicators, htmlIndicator{Indicator: ind, Matches: matchesPerIndicator[ind.Id]})
+	}
+
+	report.Stats.Matches = len(matches)
+	report.Stats.Indicators = len(matchesPerIndicator)
+	report.Stats.Modules = len(modules)
+	return report
+}
+
+func newHTMLMatch(m match.RouteMatch) htmlMatch {
+	hm := htmlMatch{
+		ID:          m.MatchId,
+		IndicatorID: m.Indicator.Id,
+		Function:    m.Indicator.Package + "." + m.Indicator.Function,
+		Module:      m.Module,
+		Handler:     m.Handler,
+		EnclosedBy:  enclosedBy(m),
+		Position:    m.Pos.String(),
+		Violations:  violationStrings(m),
+	}
+
+	for _, k := range sortedParamKeys(m.Params) {
+		name, value := paramDisplay(k, m.Params[k])
+		hm.Params = append(hm.Params, htmlParam{Name: name, Value: value})
+	}
+
+	if m.SSA == nil || m.SSA.CallPaths == nil {
+		return hm
+	}
+
+	hm.PathLimited = m.SSA.PathLimited
+	for i, path := range m.SSA.CallPaths.Paths {
+		hm.Recoverable = hm.Recoverable || path.Recoverable
+		hm.Paths = append(hm.Paths, htmlPath{
+			Index:         i + 1,
+			Nodes:         pathNodeStrings(path, ""),
+			Target:        m.SSA.TargetPos,
+			NodeLimited:   path.NodeLimited,
+			FilterLimited: path.FilterLimited,
+			Recoverable:   path.Recoverable,
+		})
+	}
+	return hm
+}
+
+// isLimited reports whether the paths of a match were cut short by any of the limits set for the analysis
+func isLimited(m match.RouteMatch) bool {
+	if m.SSA == nil {
+		return false
+	}
+	if m.SSA.PathLimited {
+		return true
+	}
+	if m.SSA.CallPaths != nil {
+		for _, path := range m.SSA.CallPaths.Paths {
+			if path.NodeLimited || path.FilterLimited {
+				return true
+			}
+		}
+	}
+	return false
+}
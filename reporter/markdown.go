@@ -0,0 +1,227 @@
+package reporter
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"sort"
+	"strings"
+)
+
+type markdownSummaryKey struct {
+	IndicatorID string
+	Function    string
+	Module      string
+}
+
+// PrintMarkdown writes a compact Markdown report meant to be posted as a code review comment: a summary table of
+// matches by indicator and module, followed by a collapsible block per match with its call paths. If maxPaths is
+// greater than zero, only the first maxPaths paths of each match are included
+func PrintMarkdown(matches []match.RouteMatch, filename string, maxPaths int) error {
+	return writeOutput([]byte(buildMarkdown(matches, maxPaths)), filename)
+}
+
+func buildMarkdown(matches []match.RouteMatch, maxPaths int) string {
+	var sb strings.Builder
+
+	sb.WriteString("## Wally report\n\n")
+	if len(matches) == 0 {
+		sb.WriteString("No matches found\n")
+		return sb.String()
+	}
+
+	writeMarkdownSummary(&sb, matches)
+	writeMarkdownViolations(&sb, matches)
+
+	sb.WriteString("\n### Matches\n\n")
+	for _, m := range matches {
+		writeMarkdownMatch(&sb, m, maxPaths)
+	}
+	return sb.String()
+}
+
+func writeMarkdownSummary(sb *strings.Builder, matches []match.RouteMatch) {
+	matchCount := make(map[markdownSummaryKey]int)
+	pathCount := make(map[markdownSummaryKey]int)
+	var keys []markdownSummaryKey
+	for _, m := range matches {
+		key := markdownSummaryKey{
+			IndicatorID: m.Indicator.Id,
+			Function:    m.Indicator.Package + "." + m.Indicator.Function,
+			Module:      m.Module,
+		}
+		if _, ok := matchCount[key]; !ok {
+			keys = append(keys, key)
+		}
+		matchCount[key]++
+		if m.SSA != nil && m.SSA.CallPaths != nil {
+			pathCount[key] += len(m.SSA.CallPaths.Paths)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].IndicatorID != keys[j].IndicatorID {
+			return keys[i].IndicatorID < keys[j].IndicatorID
+		}
+		return keys[i].Module < keys[j].Module
+	})
+
+	sb.WriteString(fmt.Sprintf("Found **%d** matches.\n\n", len(matches)))
+	sb.WriteString("| Indicator | Function | Module | Matches | Paths |\n")
+	sb.WriteString("|---|---|---|---:|---:|\n")
+	for _, key := range keys {
+		module := key.Module
+		if module == "" {
+			module = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %d |\n",
+			markdownCell(key.IndicatorID), markdownCodeCell(key.Function), markdownCell(module), matchCount[key], pathCount[key]))
+	}
+}
+
+func writeMarkdownViolations(sb *strings.Builder, matches []match.RouteMatch) {
+	var rows []string
+	for _, m := range matches {
+		for _, v := range m.Violations {
+			rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s |\n",
+				markdownMatchTitle(m), markdownCell(v.PolicyId), markdownCell(v.Message),
+				markdownCodeCell(fmt.Sprintf("%s:%d", RelativeFilename(m.Pos.Filename), m.Pos.Line))))
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("\n### Policy violations\n\nFound **%d** policy violations.\n\n", len(rows)))
+	sb.WriteString("| Match | Policy | Violation | Position |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, row := range rows {
+		sb.WriteString(row)
+	}
+}
+
+func writeMarkdownMatch(sb *strings.Builder, m match.RouteMatch, maxPaths int) {
+	var paths []*match.CallPath
+	if m.SSA != nil && m.SSA.CallPaths != nil {
+		paths = m.SSA.CallPaths.Paths
+	}
+
+	pathsSummary := fmt.Sprintf("%d paths", len(paths))
+	if m.SSA != nil && m.SSA.PathLimited {
+		pathsSummary += ", path limited"
+	}
+
+	sb.WriteString("<details>\n")
+	sb.WriteString(fmt.Sprintf("<summary>%s at <code>%s</code> (%s)</summary>\n\n",
+		markdownMatchTitle(m), htmlEscape(fmt.Sprintf("%s:%d", RelativeFilename(m.Pos.Filename), m.Pos.Line)), pathsSummary))
+
+	sb.WriteString(fmt.Sprintf("- **ID:** %s\n", markdownCode(m.MatchId)))
+	sb.WriteString(fmt.Sprintf("- **Indicator:** %s (%s)\n", markdownCell(m.Indicator.Id), markdownCode(m.Indicator.Package+"."+m.Indicator.Function)))
+	sb.WriteString(fmt.Sprintf("- **Kind:** %s\n", markdownCell(m.Indicator.Kind())))
+	if m.Indicator.Egress != "" {
+		sb.WriteString(fmt.Sprintf("- **Egress:** %s\n", markdownCell(m.Indicator.Egress)))
+	}
+	for _, k := range sortedParamKeys(m.Params) {
+		name, value := paramDisplay(k, m.Params[k])
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", markdownCell(name), markdownCode(value)))
+	}
+	if m.Handler != "" {
+		sb.WriteString(fmt.Sprintf("- **Handler:** %s\n", markdownCode(m.Handler)))
+	}
+	if m.Command != "" {
+		sb.WriteString(fmt.Sprintf("- **Command:** %s\n", markdownCode(m.Command)))
+	}
+	if m.Flags != nil {
+		sb.WriteString(fmt.Sprintf("- **Flags:** %s\n", markdownCode(listString(m.Flags))))
+	}
+	for _, st := range m.Statements() {
+		sb.WriteString(fmt.Sprintf("- **Statement:** %s\n", markdownCode(statementString(st))))
+	}
+	if m.ReachedFrom != nil {
+		sb.WriteString(fmt.Sprintf("- **Reached from:** %s\n", markdownCode(listString(m.ReachedFrom))))
+	}
+	if m.Auth != nil {
+		sb.WriteString(fmt.Sprintf("- **Auth:** %s\n", markdownCode(authString(m.Auth))))
+	}
+	if m.Middleware != nil {
+		sb.WriteString(fmt.Sprintf("- **Middleware:** %s\n", markdownCode(middlewareString(m.Middleware))))
+	}
+	for _, in := range inputStrings(m.Inputs) {
+		sb.WriteString(fmt.Sprintf("- **Input:** %s\n", markdownCode(in)))
+	}
+	for _, r := range responseStrings(m.Responses) {
+		sb.WriteString(fmt.Sprintf("- **Response:** %s\n", markdownCode(r)))
+	}
+	if eb := enclosedBy(m); eb != "" {
+		sb.WriteString(fmt.Sprintf("- **Enclosed by:** %s\n", markdownCode(eb)))
+	}
+	for _, v := range violationStrings(m) {
+		sb.WriteString(fmt.Sprintf("- **Policy violation:** %s\n", markdownCell(v)))
+	}
+
+	for i, path := range paths {
+		if maxPaths > 0 && i >= maxPaths {
+			sb.WriteString(fmt.Sprintf("\n_%d more paths not shown_\n", len(paths)-maxPaths))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n**Path %d**%s\n\n", i+1, markdownPathFlags(path)))
+		for x, node := range pathNodeStrings(path, m.SSA.TargetPos) {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", x+1, markdownCode(node)))
+		}
+	}
+
+	sb.WriteString("\n</details>\n\n")
+}
+
+func markdownMatchTitle(m match.RouteMatch) string {
+	var routes []string
+	for _, r := range m.HTTPRoutes() {
+		routes = append(routes, strings.TrimSpace(r.Method+" "+r.Path))
+	}
+	if len(routes) > 0 {
+		return "<code>" + htmlEscape(strings.Join(routes, ", ")) + "</code>"
+	}
+	return "<code>" + htmlEscape(m.Indicator.Function) + "</code>"
+}
+
+func markdownPathFlags(path *match.CallPath) string {
+	var flags []string
+	if path.NodeLimited {
+		flags = append(flags, "node limited")
+	}
+	if path.FilterLimited {
+		flags = append(flags, "filter limited")
+	}
+	if path.Recoverable {
+		flags = append(flags, "recoverable")
+	}
+	if len(flags) == 0 {
+		return ""
+	}
+	return " _(" + strings.Join(flags, ", ") + ")_"
+}
+
+// markdownCode wraps s in a code span, using a longer fence if s contains backticks
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// markdownCodeCell wraps s in a code span for a table cell, escaping pipes so that values do not break the table
+func markdownCodeCell(s string) string {
+	return markdownCode(markdownCell(s))
+}
+
+// markdownCell escapes pipes so that values do not break tables
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func htmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
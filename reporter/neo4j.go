@@ -0,0 +1,302 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Node labels and relationship types used in the Neo4j export
+const (
+	functionLabel  = "Function"
+	routeLabel     = "Route"
+	indicatorLabel = "Indicator"
+	packageLabel   = "Package"
+
+	callsRel     = "CALLS"
+	registersRel = "REGISTERS"
+	matchesRel   = "MATCHES"
+	inPackageRel = "IN_PACKAGE"
+)
+
+type neo4jNode struct {
+	ID    string
+	Label string
+	Props map[string]string
+}
+
+type neo4jRel struct {
+	Start string
+	End   string
+	Type  string
+	Site  string
+}
+
+type neo4jGraph struct {
+	nodes map[string]*neo4jNode
+	rels  map[neo4jRel]bool
+}
+
+// Property columns written for each label, in order
+var neo4jProps = map[string][]string{
+	functionLabel:  {"name", "package"},
+	routeLabel:     {"method", "path", "params", "position", "enclosed_by", "handler", "module", "match_id", "violations"},
+	indicatorLabel: {"package", "function", "receiver_type"},
+	packageLabel:   {"path"},
+}
+
+// WriteNeo4j writes the matches and their call paths to dir as CSV files in the neo4j-admin import format, along with
+// an equivalent import.cypher script. Node IDs are derived from package paths, function names, indicator IDs and
+// the content of matches, and the script uses MERGE, so running it again updates existing data rather than duplicating it
+func WriteNeo4j(matches []match.RouteMatch, dir string) error {
+	if dir == "" {
+		return fmt.Errorf("an output directory is required for the neo4j format")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %v", err)
+	}
+
+	g := buildNeo4jGraph(matches)
+
+	for _, label := range []string{functionLabel, routeLabel, indicatorLabel, packageLabel} {
+		label := label
+		fileName := filepath.Join(dir, strings.ToLower(label)+"s.csv")
+		if err := writeTableFile(fileName, ',', func(w *csv.Writer) error {
+			return g.writeNodesCSV(w, label)
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, relType := range []string{callsRel, registersRel, matchesRel, inPackageRel} {
+		relType := relType
+		fileName := filepath.Join(dir, strings.ToLower(relType)+".csv")
+		if err := writeTableFile(fileName, ',', func(w *csv.Writer) error {
+			return g.writeRelsCSV(w, relType)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, "import.cypher"), []byte(g.cypher()), 0644)
+}
+
+func buildNeo4jGraph(matches []match.RouteMatch) *neo4jGraph {
+	g := &neo4jGraph{
+		nodes: make(map[string]*neo4jNode),
+		rels:  make(map[neo4jRel]bool),
+	}
+
+	routeIDs := make(map[string]int)
+	for _, m := range matches {
+		indID := "indicator:" + m.Indicator.Id
+		g.addNode(indID, indicatorLabel, map[string]string{
+			"package":       m.Indicator.Package,
+			"function":      m.Indicator.Function,
+			"receiver_type": m.Indicator.ReceiverType,
+		})
+
+		// Identical calls in the same function are numbered in the order of the matches
+		routeID := neo4jRouteID(m)
+		if routeIDs[routeID]++; routeIDs[routeID] > 1 {
+			routeID = fmt.Sprintf("%s#%d", routeID, routeIDs[routeID])
+		}
+		method, path := "", ""
+		if routes := m.HTTPRoutes(); len(routes) > 0 {
+			method, path = routes[0].Method, routes[0].Path
+		}
+		var params []string
+		for _, k := range sortedParamKeys(m.Params) {
+			k, v := paramDisplay(k, m.Params[k])
+			params = append(params, k+"="+v)
+		}
+		g.addNode(routeID, routeLabel, map[string]string{
+			"method":      method,
+			"path":        path,
+			"params":      strings.Join(params, ";"),
+			"position":    fmt.Sprintf("%s:%d:%d", RelativeFilename(m.Pos.Filename), m.Pos.Line, m.Pos.Column),
+			"enclosed_by": enclosedBy(m),
+			"handler":     m.Handler,
+			"module":      m.Module,
+			"match_id":    m.MatchId,
+			"violations":  strings.Join(violationStrings(m), ";"),
+		})
+		g.addRel(routeID, indID, matchesRel, "")
+
+		enclosingID := ""
+		if m.SSA != nil && m.SSA.EnclosedByFunc != nil {
+			fn := m.SSA.EnclosedByFunc
+			pkgPath := ""
+			if fn.Pkg != nil {
+				pkgPath = fn.Pkg.Pkg.Path()
+			}
+			enclosingID = g.addFunction(fn.String(), fn.Name(), pkgPath)
+		} else if eb := enclosedBy(m); eb != "" {
+			enclosingID = g.addFunction(eb, eb, "")
+		}
+		if enclosingID != "" {
+			g.addRel(enclosingID, routeID, registersRel, fmt.Sprintf("%s:%d", RelativeFilename(m.Pos.Filename), m.Pos.Line))
+		}
+
+		if m.SSA == nil || m.SSA.CallPaths == nil {
+			continue
+		}
+
+		for _, path := range m.SSA.CallPaths.Paths {
+			prevID, prevSite := "", ""
+			// Nodes are stored from the match upwards, so we walk them backwards to start at the root of the path
+			for x := len(path.Nodes) - 1; x >= 0; x-- {
+				wn := path.Nodes[x]
+				if wn.Caller == nil || wn.Caller.Func == nil {
+					prevID = ""
+					continue
+				}
+				fn := wn.Caller.Func
+				pkgPath := ""
+				if fn.Pkg != nil {
+					pkgPath = fn.Pkg.Pkg.Path()
+				}
+				id := g.addFunction(fn.String(), fn.Name(), pkgPath)
+				if prevID != "" && prevID != id {
+					g.addRel(prevID, id, callsRel, prevSite)
+				}
+				pos := wn.Position()
+				prevID, prevSite = id, fmt.Sprintf("%s:%d", RelativeFilename(pos.Filename), pos.Line)
+			}
+			// The last node of a path is the function that registers the route
+			if prevID != "" && enclosingID != "" && prevID != enclosingID {
+				g.addRel(prevID, enclosingID, callsRel, prevSite)
+			}
+		}
+	}
+
+	return g
+}
+
+// neo4jRouteID identifies a match by its indicator, file, enclosing function and params. Unlike the match ID, it
+// leaves out the line and column, so that routes keep their node when lines move
+func neo4jRouteID(m match.RouteMatch) string {
+	return "route:" + match.ContentID(m.Indicator.Id, filepath.ToSlash(RelativeFilename(m.Pos.Filename)), m.EnclosedBy, m.Params)
+}
+
+func (g *neo4jGraph) addFunction(fullName, name, pkgPath string) string {
+	id := "func:" + fullName
+	g.addNode(id, functionLabel, map[string]string{"name": name, "package": pkgPath})
+	if pkgPath != "" {
+		pkgID := "pkg:" + pkgPath
+		g.addNode(pkgID, packageLabel, map[string]string{"path": pkgPath})
+		g.addRel(id, pkgID, inPackageRel, "")
+	}
+	return id
+}
+
+func (g *neo4jGraph) addNode(id, label string, props map[string]string) {
+	if _, ok := g.nodes[id]; ok {
+		return
+	}
+	g.nodes[id] = &neo4jNode{ID: id, Label: label, Props: props}
+}
+
+func (g *neo4jGraph) addRel(start, end, relType, site string) {
+	g.rels[neo4jRel{Start: start, End: end, Type: relType, Site: site}] = true
+}
+
+func (g *neo4jGraph) sortedNodes(label string) []*neo4jNode {
+	var nodes []*neo4jNode
+	for _, n := range g.nodes {
+		if n.Label == label {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+func (g *neo4jGraph) sortedRels(relType string) []neo4jRel {
+	var rels []neo4jRel
+	for r := range g.rels {
+		if r.Type == relType {
+			rels = append(rels, r)
+		}
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		if rels[i].Start != rels[j].Start {
+			return rels[i].Start < rels[j].Start
+		}
+		if rels[i].End != rels[j].End {
+			return rels[i].End < rels[j].End
+		}
+		return rels[i].Site < rels[j].Site
+	})
+	return rels
+}
+
+func (g *neo4jGraph) writeNodesCSV(w *csv.Writer, label string) error {
+	header := append([]string{"id:ID"}, neo4jProps[label]...)
+	header = append(header, ":LABEL")
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("error writing header to CSV: %v", err)
+	}
+	for _, n := range g.sortedNodes(label) {
+		row := []string{n.ID}
+		for _, p := range neo4jProps[label] {
+			row = append(row, n.Props[p])
+		}
+		row = append(row, n.Label)
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("error writing record to CSV: %v", err)
+		}
+	}
+	return nil
+}
+
+func (g *neo4jGraph) writeRelsCSV(w *csv.Writer, relType string) error {
+	if err := w.Write([]string{":START_ID", ":END_ID", ":TYPE", "site"}); err != nil {
+		return fmt.Errorf("error writing header to CSV: %v", err)
+	}
+	for _, r := range g.sortedRels(relType) {
+		if err := w.Write([]string{r.Start, r.End, r.Type, r.Site}); err != nil {
+			return fmt.Errorf("error writing record to CSV: %v", err)
+		}
+	}
+	return nil
+}
+
+func (g *neo4jGraph) cypher() string {
+	var sb strings.Builder
+	labels := []string{functionLabel, routeLabel, indicatorLabel, packageLabel}
+
+	for _, label := range labels {
+		sb.WriteString(fmt.Sprintf("CREATE CONSTRAINT wally_%s_id IF NOT EXISTS FOR (n:%s) REQUIRE n.id IS UNIQUE;\n", strings.ToLower(label), label))
+	}
+	sb.WriteString("\n")
+
+	for _, label := range labels {
+		for _, n := range g.sortedNodes(label) {
+			var sets []string
+			for _, p := range neo4jProps[label] {
+				sets = append(sets, fmt.Sprintf("n.%s = %s", p, cypherString(n.Props[p])))
+			}
+			sb.WriteString(fmt.Sprintf("MERGE (n:%s {id: %s}) SET %s;\n", label, cypherString(n.ID), strings.Join(sets, ", ")))
+		}
+	}
+	sb.WriteString("\n")
+
+	for _, relType := range []string{callsRel, registersRel, matchesRel, inPackageRel} {
+		for _, r := range g.sortedRels(relType) {
+			start, end := g.nodes[r.Start], g.nodes[r.End]
+			sb.WriteString(fmt.Sprintf("MATCH (a:%s {id: %s}), (b:%s {id: %s}) MERGE (a)-[:%s {site: %s}]->(b);\n",
+				start.Label, cypherString(start.ID), end.Label, cypherString(end.ID), r.Type, cypherString(r.Site)))
+		}
+	}
+
+	return sb.String()
+}
+
+func cypherString(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n", "\r", "\\r", "\t", "\\t").Replace(s) + "\""
+}
@@ -0,0 +1,292 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/wallylib"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const openAPIVersion = "3.0.3"
+
+type openAPIDoc struct {
+	OpenAPI    string                                 `json:"openapi" yaml:"openapi"`
+	Info       openAPIInfo                            `json:"info" yaml:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths" yaml:"paths"`
+	Unresolved []openAPIUnresolved                    `json:"x-wally-unresolved,omitempty" yaml:"x-wally-unresolved,omitempty"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+type openAPIOperation struct {
+	OperationID      string                     `json:"operationId" yaml:"operationId"`
+	Summary          string                     `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Parameters       []openAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Responses        map[string]openAPIResponse `json:"responses" yaml:"responses"`
+	Handler          string                     `json:"x-wally-handler,omitempty" yaml:"x-wally-handler,omitempty"`
+	Position         string                     `json:"x-wally-position" yaml:"x-wally-position"`
+	MatchID          string                     `json:"x-wally-match-id" yaml:"x-wally-match-id"`
+	MethodUnresolved bool                       `json:"x-wally-method-unresolved,omitempty" yaml:"x-wally-method-unresolved,omitempty"`
+	Violations       []string                   `json:"x-wally-violations,omitempty" yaml:"x-wally-violations,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string        `json:"name" yaml:"name"`
+	In       string        `json:"in" yaml:"in"`
+	Required bool          `json:"required" yaml:"required"`
+	Schema   openAPISchema `json:"schema" yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Type       string                   `json:"type" yaml:"type"`
+	Items      *openAPISchema           `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties map[string]openAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	GoType     string                   `json:"x-go-type,omitempty" yaml:"x-go-type,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description" yaml:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema openAPISchema `json:"schema" yaml:"schema"`
+}
+
+type openAPIUnresolved struct {
+	MatchID     string            `json:"matchId" yaml:"matchId"`
+	IndicatorID string            `json:"indicatorId" yaml:"indicatorId"`
+	Function    string            `json:"function" yaml:"function"`
+	Params      map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	Handler     string            `json:"handler,omitempty" yaml:"handler,omitempty"`
+	Position    string            `json:"position" yaml:"position"`
+	Violations  []string          `json:"violations,omitempty" yaml:"violations,omitempty"`
+}
+
+// Matches wildcards in the form {name}, {name...}, {name:regex}, :name and *name
+var pathWildcardRe = regexp.MustCompile(`\{([^}/:.$]+)(?:\.\.\.)?(?::[^}]*)?\}|/:([^/]+)|/\*([^/]+)`)
+
+// PrintOpenAPI writes an OpenAPI 3 document with one operation per route found in matches. Routes without a
+// resolved method are added as GET operations marked with x-wally-method-unresolved. Matches for which no path
+// could be resolved are listed under x-wally-unresolved. The document is written as YAML unless filename ends in .json
+func PrintOpenAPI(matches []match.RouteMatch, filename string) error {
+	doc := buildOpenAPIDoc(matches)
+
+	var out []byte
+	var err error
+	if strings.HasSuffix(filename, ".json") {
+		out, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		out, err = yaml.Marshal(doc)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(out, filename)
+}
+
+func buildOpenAPIDoc(matches []match.RouteMatch) openAPIDoc {
+	doc := openAPIDoc{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title:       "wally route inventory",
+			Description: "Generated by wally from route registrations found in code",
+			Version:     "0.0.0",
+		},
+		Paths: make(map[string]map[string]openAPIOperation),
+	}
+
+	for _, m := range matches {
+		// Outbound calls are not routes of the service
+		if m.Indicator.Egress != "" {
+			continue
+		}
+		routes := m.HTTPRoutes()
+		if len(routes) == 0 {
+			doc.Unresolved = append(doc.Unresolved, openAPIUnresolved{
+				MatchID:     m.MatchId,
+				IndicatorID: m.Indicator.Id,
+				Function:    m.Indicator.Package + "." + m.Indicator.Function,
+				Params:      m.Params,
+				Handler:     m.Handler,
+				Position:    m.Pos.String(),
+				Violations:  violationStrings(m),
+			})
+			continue
+		}
+
+		for i, route := range routes {
+			path, params := openAPIPath(route.Path)
+			method := strings.ToLower(route.Method)
+			op := openAPIOperation{
+				OperationID: m.MatchId,
+				Summary:     m.Handler,
+				Parameters:  params,
+				Responses:   openAPIResponses(m.Responses),
+				Handler:     m.Handler,
+				Position:    m.Pos.String(),
+				MatchID:     m.MatchId,
+				Violations:  violationStrings(m),
+			}
+			if method == "" {
+				method = "get"
+				op.MethodUnresolved = true
+			}
+
+			if _, ok := doc.Paths[path]; !ok {
+				doc.Paths[path] = make(map[string]openAPIOperation)
+			}
+			// Keep the first registration if the same route is registered more than once
+			if _, exists := doc.Paths[path][method]; exists {
+				continue
+			}
+			// Operation IDs must be unique, so matches resulting in multiple routes get a suffix
+			if len(routes) > 1 {
+				op.OperationID = fmt.Sprintf("%s-%d", m.MatchId, i+1)
+			}
+			doc.Paths[path][method] = op
+		}
+	}
+
+	return doc
+}
+
+// openAPIResponses returns a response per status code written by the handler, with the bodies encoded for it. Bodies
+// written without a status code, as with json.NewEncoder(w).Encode(v), are added to the lowest 2xx status code, or
+// to 200 as that is what net/http responds with by default
+func openAPIResponses(res *wallylib.Responses) map[string]openAPIResponse {
+	if res == nil {
+		return map[string]openAPIResponse{
+			"default": {Description: "Response not analyzed"},
+		}
+	}
+
+	defaultStatus := 0
+	for _, code := range res.StatusCodes {
+		if code >= 200 && code < 300 {
+			defaultStatus = code
+			break
+		}
+	}
+
+	responses := make(map[string]openAPIResponse)
+	addStatus := func(code int) openAPIResponse {
+		key := strconv.Itoa(code)
+		if _, ok := responses[key]; !ok {
+			desc := http.StatusText(code)
+			if desc == "" {
+				desc = "Status " + key
+			}
+			responses[key] = openAPIResponse{Description: desc}
+		}
+		return responses[key]
+	}
+	for _, code := range res.StatusCodes {
+		addStatus(code)
+	}
+
+	for _, body := range res.Bodies {
+		code := body.Status
+		if code == 0 {
+			code = defaultStatus
+		}
+		if code == 0 {
+			code = http.StatusOK
+		}
+		resp := addStatus(code)
+		if resp.Content == nil {
+			resp.Content = make(map[string]openAPIMediaType)
+		}
+		mediaType := "application/" + body.Encoding
+		// Keep the first body if different types are encoded for the same status code
+		if _, exists := resp.Content[mediaType]; !exists {
+			resp.Content[mediaType] = openAPIMediaType{Schema: openAPIBodySchema(body)}
+		}
+		responses[strconv.Itoa(code)] = resp
+	}
+
+	if len(responses) == 0 {
+		addStatus(http.StatusOK)
+	}
+	return responses
+}
+
+func openAPIBodySchema(body wallylib.ResponseBody) openAPISchema {
+	schema := openAPISchema{Type: openAPIType(body.Type), GoType: body.Type}
+	target := &schema
+	if schema.Type == "array" {
+		schema.Items = &openAPISchema{Type: openAPIType(strings.TrimPrefix(body.Type, "[]"))}
+		target = schema.Items
+	}
+	if len(body.Fields) > 0 {
+		target.Type = "object"
+		target.Properties = make(map[string]openAPISchema)
+		for _, f := range body.Fields {
+			target.Properties[f.Key] = openAPISchema{Type: openAPIType(f.Type), GoType: f.Type}
+		}
+	}
+	return schema
+}
+
+// openAPIType maps a Go type to an OpenAPI schema type
+func openAPIType(goType string) string {
+	goType = strings.TrimLeft(goType, "*")
+	switch {
+	case goType == "[]byte":
+		return "string"
+	case strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "["):
+		return "array"
+	case goType == "string":
+		return "string"
+	case goType == "bool":
+		return "boolean"
+	case strings.HasPrefix(goType, "int"), strings.HasPrefix(goType, "uint"):
+		return "integer"
+	case strings.HasPrefix(goType, "float"):
+		return "number"
+	}
+	return "object"
+}
+
+// openAPIPath converts router wildcards to OpenAPI path templates and returns the inferred path parameters. Paths
+// relative to a group whose prefix was not resolved, as in users/:id, are made absolute as OpenAPI requires
+func openAPIPath(path string) (string, []openAPIParameter) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	var params []openAPIParameter
+	seen := make(map[string]bool)
+	converted := pathWildcardRe.ReplaceAllStringFunc(path, func(s string) string {
+		sub := pathWildcardRe.FindStringSubmatch(s)
+		name, prefix := sub[1], ""
+		if sub[2] != "" {
+			name, prefix = sub[2], "/"
+		} else if sub[3] != "" {
+			name, prefix = sub[3], "/"
+		}
+		if !seen[name] {
+			seen[name] = true
+			params = append(params, openAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   openAPISchema{Type: "string"},
+			})
+		}
+		return prefix + "{" + name + "}"
+	})
+
+	// "/{$}" matches the exact path in net/http
+	converted = strings.TrimSuffix(converted, "{$}")
+	return converted, params
+}
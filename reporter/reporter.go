@@ -4,14 +4,23 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"github.com/goccy/go-graphviz"
-	"github.com/goccy/go-graphviz/cgraph"
 	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/wallylib"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// Options holds settings for output formats that need more than an output file name
+type Options struct {
+	// PathsFile is an optional second file for tabular formats with one row per call path
+	PathsFile string
+	// MaxPathsInReport limits the number of call paths per match included in markdown reports
+	MaxPathsInReport int
+}
+
 func PrintResults(matches []match.RouteMatch) {
 	for _, match := range matches {
 		// TODO: This is printing the values from the indicator
@@ -19,7 +28,16 @@ func PrintResults(matches []match.RouteMatch) {
 		// from those captured during navigator, just in case
 		PrintMach(match)
 	}
+	printCommandTree(matches)
 	fmt.Println("Total Results: ", len(matches))
+
+	violations := 0
+	for _, m := range matches {
+		violations += len(m.Violations)
+	}
+	if violations > 0 {
+		fmt.Println("Policy Violations: ", violations)
+	}
 }
 
 func PrintMach(match match.RouteMatch) {
@@ -28,6 +46,10 @@ func PrintMach(match match.RouteMatch) {
 	fmt.Println("Indicator ID: ", match.Indicator.Id)
 	fmt.Println("Package: ", match.Indicator.Package)
 	fmt.Println("Function: ", match.Indicator.Function)
+	fmt.Println("Kind: ", match.Indicator.Kind())
+	if match.Indicator.Egress != "" {
+		fmt.Println("Egress: ", match.Indicator.Egress)
+	}
 	fmt.Println("Module: ", match.Module)
 	fmt.Println("Params: ")
 	for k, v := range match.Params {
@@ -40,6 +62,72 @@ func PrintMach(match match.RouteMatch) {
 		fmt.Printf("	%s: %s\n", k, v)
 	}
 
+	if match.Handler != "" {
+		fmt.Println("Handler: ", match.Handler)
+	}
+
+	if match.Command != "" {
+		fmt.Println("Command: ", match.Command)
+	}
+
+	if match.Parent != "" {
+		fmt.Println("Parent: ", match.Parent)
+	}
+
+	if match.Flags != nil {
+		fmt.Println("Flags: ", listString(match.Flags))
+	}
+
+	for _, st := range match.Statements() {
+		fmt.Println("Statement: ", statementString(st))
+	}
+
+	if match.ReachedFrom != nil {
+		fmt.Println("Reached from: ", listString(match.ReachedFrom))
+	}
+
+	if match.Auth != nil {
+		fmt.Println("Auth: ", authString(match.Auth))
+	}
+
+	if match.Middleware != nil {
+		fmt.Println("Middleware: ", middlewareString(match.Middleware))
+	}
+
+	if match.Inputs != nil && len(match.Inputs) == 0 {
+		fmt.Println("Inputs:  none")
+	} else if match.Inputs != nil {
+		fmt.Println("Inputs: ")
+		for _, in := range match.Inputs {
+			fmt.Printf("	%s\n", inputString(in))
+		}
+	}
+
+	if match.Responses != nil {
+		fmt.Println("Responses: ")
+		for _, r := range responseStrings(match.Responses) {
+			fmt.Printf("	%s\n", r)
+		}
+	}
+
+	if match.Suppression != nil {
+		fmt.Printf("Suppressed: %s", match.Suppression.Source)
+		if match.Suppression.Reason != "" {
+			fmt.Printf(" (%s)", match.Suppression.Reason)
+		}
+		if match.Suppression.Expires != "" {
+			fmt.Printf(" until %s", match.Suppression.Expires)
+		}
+		fmt.Println()
+	}
+
+	if len(match.Violations) > 0 {
+		fmt.Println("Policy violations: ")
+		for _, v := range match.Violations {
+			fmt.Printf("	%s\n", violationString(v))
+		}
+	}
+
 	if match.SSA != nil && match.SSA.EnclosedByFunc != nil {
 		fmt.Println("Enclosed by: ", match.SSA.EnclosedByFunc.String())
 	} else {
@@ -76,6 +164,93 @@ func PrintMach(match match.RouteMatch) {
 	fmt.Println()
 }
 
+func authString(auth []string) string {
+	if len(auth) == 0 {
+		return "none"
+	}
+	return strings.Join(auth, " -> ")
+}
+
+// middlewareString formats a middleware chain from the outermost to the innermost middleware, flagging those that
+// recover from panics
+func middlewareString(chain []wallylib.Middleware) string {
+	if len(chain) == 0 {
+		return "none"
+	}
+	var names []string
+	for _, mw := range chain {
+		name := mw.Name
+		if mw.Recovers {
+			name += " (recovers)"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " -> ")
+}
+
+func inputString(in wallylib.Input) string {
+	if in.Name == "" {
+		return fmt.Sprintf("%s (%s)", in.Kind, in.Type)
+	}
+	return fmt.Sprintf("%s %s (%s)", in.Kind, in.Name, in.Type)
+}
+
+func inputStrings(inputs []wallylib.Input) []string {
+	var res []string
+	for _, in := range inputs {
+		res = append(res, inputString(in))
+	}
+	return res
+}
+
+// responseStrings describes the status codes, content types and bodies of responses, one per line
+func responseStrings(res *wallylib.Responses) []string {
+	if res == nil {
+		return nil
+	}
+	var lines []string
+	if len(res.StatusCodes) > 0 {
+		var codes []string
+		for _, c := range res.StatusCodes {
+			codes = append(codes, strconv.Itoa(c))
+		}
+		lines = append(lines, "status "+strings.Join(codes, ", "))
+	}
+	if len(res.ContentTypes) > 0 {
+		lines = append(lines, "content-type "+strings.Join(res.ContentTypes, ", "))
+	}
+	for _, body := range res.Bodies {
+		line := body.Encoding + " " + body.Type
+		if body.Status > 0 {
+			line = strconv.Itoa(body.Status) + " " + line
+		}
+		if len(body.Fields) > 0 {
+			var fields []string
+			for _, f := range body.Fields {
+				fields = append(fields, f.Key+" "+f.Type)
+			}
+			line += " {" + strings.Join(fields, ", ") + "}"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "none")
+	}
+	return lines
+}
+
+func violationString(v match.PolicyViolation) string {
+	return fmt.Sprintf("%s: %s", v.PolicyId, v.Message)
+}
+
+func violationStrings(m match.RouteMatch) []string {
+	var res []string
+	for _, v := range m.Violations {
+		res = append(res, violationString(v))
+	}
+	return res
+}
+
 func GetJson(matches []match.RouteMatch) []byte {
 	jsonOutput, err := json.Marshal(matches)
 	if err != nil {
@@ -92,93 +267,119 @@ func PrintJson(matches []match.RouteMatch, filename string) error {
 		return err
 	}
 
-	if filename != "" {
-		file, err := os.Create(filename)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer file.Close()
+	return writeOutput(jsonOutput, filename)
+}
 
-		// Write data to the file
-		_, err = file.Write(jsonOutput)
-		if err != nil {
-			log.Fatal(err)
+// RelativeFilename makes file names relative to the working directory, which is where wally expects to be run from
+func RelativeFilename(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
 		}
-	} else {
-		fmt.Println(string(jsonOutput))
 	}
-	return nil
+	return filename
 }
 
-func WriteCSVFile(matches []match.RouteMatch, filePath string) error {
-	file, err := os.Create(filePath)
+// writeOutput writes data to filename, or to stdout if no filename is given
+func writeOutput(data []byte, filename string) error {
+	if filename == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("error creating file: %v", err)
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Writing the header of the CSV file
-	if err := writer.Write([]string{"source", "target"}); err != nil {
-		return fmt.Errorf("error writing header to CSV: %v", err)
+	if _, err = file.Write(data); err != nil {
+		return fmt.Errorf("error writing to file: %v", err)
 	}
+	return nil
+}
 
-	for _, match := range matches {
-		if match.SSA != nil && match.SSA.CallPaths != nil {
-			for _, paths := range match.SSA.CallPaths.Paths {
-				for i := 0; i < len(paths.Nodes)-1; i++ {
-					if err := writer.Write([]string{paths.Nodes[i].NodeString, paths.Nodes[i+1].NodeString}); err != nil {
-						return fmt.Errorf("error writing record to CSV: %v", err)
+// WriteCSVFile writes one source,target row per edge between the nodes of every call path
+func WriteCSVFile(matches []match.RouteMatch, filePath string) error {
+	return writeTableFile(filePath, ',', func(writer *csv.Writer) error {
+		// Writing the header of the CSV file
+		if err := writer.Write([]string{"source", "target"}); err != nil {
+			return fmt.Errorf("error writing header to CSV: %v", err)
+		}
+
+		for _, match := range matches {
+			if match.SSA != nil && match.SSA.CallPaths != nil {
+				for _, paths := range match.SSA.CallPaths.Paths {
+					for i := 0; i < len(paths.Nodes)-1; i++ {
+						if err := writer.Write([]string{paths.Nodes[i].NodeString, paths.Nodes[i+1].NodeString}); err != nil {
+							return fmt.Errorf("error writing record to CSV: %v", err)
+						}
 					}
 				}
 			}
 		}
-	}
+		return nil
+	})
+}
 
-	return nil
+// listString joins values, such as the flags of a command, or returns none if there are none
+func listString(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
 }
 
-// TODO: Move this to a new package dedicated to graphing, or in this same package but in a separate file
-func GenerateGraph(matches []match.RouteMatch, path string) {
-	g := graphviz.New()
-	graph, err := g.Graph()
-	if err != nil {
-		log.Fatal(err)
+// printCommandTree prints the CLI commands among matches as a tree, with the handler of each command
+func printCommandTree(matches []match.RouteMatch) {
+	children := make(map[string][]match.RouteMatch)
+	ids := make(map[string]bool)
+	for _, m := range matches {
+		if m.Command != "" {
+			ids[m.MatchId] = true
+		}
 	}
-	for _, match := range matches {
-		for _, paths := range match.SSA.CallPaths.Paths {
-			var prev *cgraph.Node
-			for i := 0; i < len(paths.Nodes); i++ {
-				if i == 0 {
-					prev, err = graph.CreateNode(paths.Nodes[i].NodeString)
-					if err != nil {
-						log.Fatal(err)
-					}
-					prev = prev.SetColor("red").SetFillColor("blue").SetShape("diamond")
-				} else {
-					newNode, err := graph.CreateNode(paths.Nodes[i].NodeString)
-					if err != nil {
-						log.Fatal(err)
-					}
-					_, err = graph.CreateEdge("e", newNode, prev)
-					if err != nil {
-						log.Fatal(err)
-					}
-					prev = newNode
-				}
-			}
+	var roots []match.RouteMatch
+	for _, m := range matches {
+		if m.Command == "" {
+			continue
+		}
+		if ids[m.Parent] {
+			children[m.Parent] = append(children[m.Parent], m)
+		} else {
+			roots = append(roots, m)
 		}
 	}
+	if len(roots) == 0 {
+		return
+	}
 
-	if strings.HasSuffix(path, ".png") {
-		if err := g.RenderFilename(graph, graphviz.PNG, path); err != nil {
-			log.Fatal(err)
+	fmt.Println("===========COMMANDS============")
+	var printCommand func(m match.RouteMatch, depth int)
+	printCommand = func(m match.RouteMatch, depth int) {
+		line := strings.Repeat("  ", depth) + m.Command
+		if depth > 0 {
+			fields := strings.Fields(m.Command)
+			line = strings.Repeat("  ", depth) + fields[len(fields)-1]
+		}
+		if m.Handler != "" {
+			line += " -> " + m.Handler
 		}
-	} else if strings.HasSuffix(path, ".xdot") {
-		if err := g.RenderFilename(graph, graphviz.XDOT, path); err != nil {
-			log.Fatal(err)
+		if len(m.Flags) > 0 {
+			line += " [" + listString(m.Flags) + "]"
 		}
+		fmt.Println(line)
+		if depth > len(matches) {
+			return
+		}
+		for _, child := range children[m.MatchId] {
+			printCommand(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		printCommand(root, 0)
 	}
 }
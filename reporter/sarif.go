@@ -0,0 +1,345 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	ShortDescription sarifMessage      `json:"shortDescription"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	CodeFlows    []sarifCodeFlow    `json:"codeFlows,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	Properties   map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Message   *sarifMessage             `json:"message,omitempty"`
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
+}
+
+// PrintSarif writes a SARIF 2.1.0 log with a rule per indicator and a result per match.
+// Each call path of a match is encoded as a thread flow going from the root of the path to the match
+func PrintSarif(indicators []indicator.Indicator, matches []match.RouteMatch, filename string) error {
+	sarifOutput, err := json.MarshalIndent(buildSarifLog(indicators, matches), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(sarifOutput, filename)
+}
+
+func buildSarifLog(indicators []indicator.Indicator, matches []match.RouteMatch) sarifLog {
+	rules := []sarifRule{}
+	ruleIdx := make(map[string]int)
+	addRule := func(ind indicator.Indicator) {
+		if _, ok := ruleIdx[ind.Id]; ok {
+			return
+		}
+		ruleIdx[ind.Id] = len(rules)
+		rules = append(rules, newSarifRule(ind))
+	}
+
+	for _, ind := range indicators {
+		addRule(ind)
+	}
+
+	results := []sarifResult{}
+	for _, m := range matches {
+		// Matches should always come from a known indicator, but we do not want to produce an invalid ruleIndex
+		addRule(m.Indicator)
+		results = append(results, newSarifResult(m, ruleIdx[m.Indicator.Id]))
+
+		// Policy violations are reported as errors of their own rule, so that they can fail code scanning checks
+		for _, v := range m.Violations {
+			id := policyRuleID(v.PolicyId)
+			if _, ok := ruleIdx[id]; !ok {
+				ruleIdx[id] = len(rules)
+				rules = append(rules, newPolicySarifRule(v))
+			}
+			results = append(results, newPolicySarifResult(m, v, ruleIdx[id]))
+		}
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "wally",
+						InformationURI: "https://github.com/hex0punk/wally",
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func newSarifRule(ind indicator.Indicator) sarifRule {
+	funcName := fmt.Sprintf("%s.%s", ind.Package, ind.Function)
+	if ind.ReceiverType != "" {
+		funcName = fmt.Sprintf("%s.%s.%s", ind.Package, ind.ReceiverType, ind.Function)
+	}
+
+	props := map[string]string{
+		"package":  ind.Package,
+		"function": ind.Function,
+	}
+	if ind.ReceiverType != "" {
+		props["receiverType"] = ind.ReceiverType
+	}
+
+	return sarifRule{
+		ID:               ind.Id,
+		Name:             funcName,
+		ShortDescription: sarifMessage{Text: fmt.Sprintf("Call to %s", funcName)},
+		Properties:       props,
+	}
+}
+
+func policyRuleID(policyID string) string {
+	return "policy/" + policyID
+}
+
+func newPolicySarifRule(v match.PolicyViolation) sarifRule {
+	desc := v.Description
+	if desc == "" {
+		desc = fmt.Sprintf("Policy %s", v.PolicyId)
+	}
+	return sarifRule{
+		ID:               policyRuleID(v.PolicyId),
+		Name:             v.PolicyId,
+		ShortDescription: sarifMessage{Text: desc},
+	}
+}
+
+func newPolicySarifResult(m match.RouteMatch, v match.PolicyViolation, ruleIndex int) sarifResult {
+	result := sarifResult{
+		RuleID:    policyRuleID(v.PolicyId),
+		RuleIndex: ruleIndex,
+		Level:     "error",
+		Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", sarifResultMessage(m), v.Message)},
+		Locations: []sarifLocation{newSarifLocation(m.Pos, nil)},
+		Properties: map[string]string{
+			"matchId": m.MatchId,
+			"policy":  v.PolicyId,
+		},
+	}
+	result.Suppressions = sarifSuppressions(m)
+	return result
+}
+
+func sarifSuppressions(m match.RouteMatch) []sarifSuppression {
+	if m.Suppression == nil {
+		return nil
+	}
+	// SARIF distinguishes suppressions in source code from those kept in a separate file such as a baseline
+	kind := "external"
+	if m.Suppression.Source == match.InlineSuppression {
+		kind = "inSource"
+	}
+	return []sarifSuppression{{Kind: kind, Justification: m.Suppression.Reason}}
+}
+
+func newSarifResult(m match.RouteMatch, ruleIndex int) sarifResult {
+	result := sarifResult{
+		RuleID:    m.Indicator.Id,
+		RuleIndex: ruleIndex,
+		Level:     "note",
+		Message:   sarifMessage{Text: sarifResultMessage(m)},
+		Locations: []sarifLocation{newSarifLocation(m.Pos, nil)},
+		Properties: map[string]string{
+			"matchId":    m.MatchId,
+			"module":     m.Module,
+			"enclosedBy": enclosedBy(m),
+		},
+	}
+
+	result.Suppressions = sarifSuppressions(m)
+
+	if m.SSA == nil || m.SSA.CallPaths == nil {
+		return result
+	}
+
+	for i, path := range m.SSA.CallPaths.Paths {
+		var locs []sarifThreadFlowLocation
+		// Nodes are stored from the match upwards, so we walk them backwards to start at the root of the path
+		for x := len(path.Nodes) - 1; x >= 0; x-- {
+			node := path.Nodes[x]
+			msg := &sarifMessage{Text: node.NodeString}
+			locs = append(locs, sarifThreadFlowLocation{Location: newSarifLocation(node.Position(), msg)})
+		}
+		target := m.SSA.TargetPos
+		if target == "" {
+			target = fmt.Sprintf("%s.%s", m.Indicator.Package, m.Indicator.Function)
+		}
+		locs = append(locs, sarifThreadFlowLocation{Location: newSarifLocation(m.Pos, &sarifMessage{Text: target})})
+
+		result.CodeFlows = append(result.CodeFlows, sarifCodeFlow{
+			ThreadFlows: []sarifThreadFlow{
+				{
+					Message:   &sarifMessage{Text: fmt.Sprintf("Path %d%s", i+1, pathFlags(path))},
+					Locations: locs,
+				},
+			},
+		})
+	}
+
+	return result
+}
+
+func sarifResultMessage(m match.RouteMatch) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Call to %s.%s", m.Indicator.Package, m.Indicator.Function))
+	if len(m.Params) > 0 {
+		var params []string
+		for _, k := range sortedParamKeys(m.Params) {
+			k, v := paramDisplay(k, m.Params[k])
+			params = append(params, fmt.Sprintf("%s: %s", k, v))
+		}
+		sb.WriteString(fmt.Sprintf(" (%s)", strings.Join(params, ", ")))
+	}
+	if m.SSA != nil && m.SSA.CallPaths != nil {
+		sb.WriteString(fmt.Sprintf(" with %d possible paths", len(m.SSA.CallPaths.Paths)))
+	}
+	return sb.String()
+}
+
+func newSarifLocation(pos token.Position, msg *sarifMessage) sarifLocation {
+	loc := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: sarifURI(pos.Filename)},
+		},
+		Message: msg,
+	}
+	if pos.Line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: pos.Line, StartColumn: pos.Column}
+	}
+	return loc
+}
+
+func sarifURI(filename string) string {
+	return filepath.ToSlash(RelativeFilename(filename))
+}
+
+func pathFlags(path *match.CallPath) string {
+	flags := ""
+	if path.NodeLimited {
+		flags += " (node limited)"
+	}
+	if path.FilterLimited {
+		flags += " (filter limited)"
+	}
+	if path.Recoverable {
+		flags += " (RECOVERABLE)"
+	}
+	return flags
+}
+
+func enclosedBy(m match.RouteMatch) string {
+	if m.SSA != nil && m.SSA.EnclosedByFunc != nil {
+		return m.SSA.EnclosedByFunc.String()
+	}
+	return m.EnclosedBy
+}
+
+func sortedParamKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func paramDisplay(k, v string) (string, string) {
+	if v == "" {
+		v = "<could not resolve>"
+	}
+	if k == "" {
+		k = "<not specified>"
+	}
+	return k, v
+}
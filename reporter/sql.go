@@ -0,0 +1,98 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"strings"
+)
+
+type sqlInventory struct {
+	Queries []sqlQuery `json:"queries"`
+	// Dynamic counts the statements built with non-constant parts
+	Dynamic int `json:"dynamic"`
+}
+
+type sqlQuery struct {
+	MatchID    string         `json:"matchId"`
+	Module     string         `json:"module,omitempty"`
+	Function   string         `json:"function"`
+	Position   string         `json:"position"`
+	EnclosedBy string         `json:"enclosedBy"`
+	Statements []sqlStatement `json:"statements"`
+	// ReachedFrom is omitted when call paths were not solved
+	ReachedFrom *[]egressRoute `json:"reachedFrom,omitempty"`
+}
+
+type sqlStatement struct {
+	Query   string   `json:"query"`
+	Verb    string   `json:"verb,omitempty"`
+	Tables  []string `json:"tables"`
+	Dynamic bool     `json:"dynamic"`
+}
+
+// PrintSQL writes the SQL statements among matches as JSON, with the verb and tables of each statement, whether it
+// is built with non-constant parts, and the routes reaching it when call paths were solved
+func PrintSQL(matches []match.RouteMatch, filename string) error {
+	out, err := json.MarshalIndent(buildSQLInventory(matches), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(out, filename)
+}
+
+func buildSQLInventory(matches []match.RouteMatch) sqlInventory {
+	byID := make(map[string]match.RouteMatch, len(matches))
+	for _, m := range matches {
+		byID[m.MatchId] = m
+	}
+
+	inventory := sqlInventory{Queries: []sqlQuery{}}
+	for _, m := range matches {
+		if !m.Indicator.SQL {
+			continue
+		}
+		query := sqlQuery{
+			MatchID:    m.MatchId,
+			Module:     m.Module,
+			Function:   m.Indicator.Package + "." + m.Indicator.Function,
+			Position:   fmt.Sprintf("%s:%d", RelativeFilename(m.Pos.Filename), m.Pos.Line),
+			EnclosedBy: m.EnclosedBy,
+			Statements: []sqlStatement{},
+		}
+		for _, st := range m.Statements() {
+			if st.Dynamic {
+				inventory.Dynamic++
+			}
+			tables := st.Tables
+			if tables == nil {
+				tables = []string{}
+			}
+			query.Statements = append(query.Statements, sqlStatement{Query: st.Query, Verb: st.Verb, Tables: tables, Dynamic: st.Dynamic})
+		}
+		if m.ReachedFrom != nil {
+			routes := []egressRoute{}
+			for _, id := range m.ReachedFrom {
+				if route, ok := byID[id]; ok {
+					routes = append(routes, egressRouteOf(route))
+				}
+			}
+			query.ReachedFrom = &routes
+		}
+		inventory.Queries = append(inventory.Queries, query)
+	}
+	return inventory
+}
+
+// statementString summarizes a statement by its verb and tables, as in SELECT users, orders (dynamic)
+func statementString(st match.Statement) string {
+	verb := st.Verb
+	if verb == "" {
+		verb = "unknown"
+	}
+	s := strings.TrimSpace(verb + " " + strings.Join(st.Tables, ", "))
+	if st.Dynamic {
+		s += " (dynamic)"
+	}
+	return s
+}
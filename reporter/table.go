@@ -0,0 +1,190 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/wallylib"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const paramColumnPrefix = "param:"
+
+// WriteMatchesTable writes one row per match to filePath using comma as the field separator.
+// Each resolved param gets its own column. If pathsFilePath is not empty, a second table with
+// one row per call path is written to it as well
+func WriteMatchesTable(matches []match.RouteMatch, filePath string, pathsFilePath string, comma rune) error {
+	if err := writeTableFile(filePath, comma, func(w *csv.Writer) error {
+		return writeMatchesTable(w, matches)
+	}); err != nil {
+		return err
+	}
+
+	if pathsFilePath == "" {
+		return nil
+	}
+
+	return writeTableFile(pathsFilePath, comma, func(w *csv.Writer) error {
+		return writePathsTable(w, matches)
+	})
+}
+
+func writeTableFile(filePath string, comma rune, write func(w *csv.Writer) error) error {
+	var out io.Writer = os.Stdout
+	if filePath != "" {
+		file, err := os.Create(filePath)
+		if err != nil {
+			return fmt.Errorf("error creating file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Comma = comma
+	if err := write(writer); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMatchesTable(writer *csv.Writer, matches []match.RouteMatch) error {
+	paramNames := matchParamNames(matches)
+
+	header := []string{"id", "indicator_id", "package", "function", "kind", "module"}
+	for _, p := range paramNames {
+		if p == "" {
+			p = "<not specified>"
+		}
+		header = append(header, paramColumnPrefix+p)
+	}
+	header = append(header, "enclosed_by", "position", "paths", "path_limited", "node_limited", "filter_limited", "recoverable_paths", "auth", "middleware", "inputs", "responses", "violations")
+
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header to CSV: %v", err)
+	}
+
+	for _, m := range matches {
+		row := []string{m.MatchId, m.Indicator.Id, m.Indicator.Package, m.Indicator.Function, m.Indicator.Kind(), m.Module}
+		for _, p := range paramNames {
+			v, ok := m.Params[p]
+			if ok && v == "" {
+				v = "<could not resolve>"
+			}
+			row = append(row, v)
+		}
+
+		numPaths, nodeLimited, filterLimited, recoverable := 0, false, false, 0
+		pathLimited := false
+		if m.SSA != nil {
+			pathLimited = m.SSA.PathLimited
+			if m.SSA.CallPaths != nil {
+				numPaths = len(m.SSA.CallPaths.Paths)
+				for _, path := range m.SSA.CallPaths.Paths {
+					nodeLimited = nodeLimited || path.NodeLimited
+					filterLimited = filterLimited || path.FilterLimited
+					if path.Recoverable {
+						recoverable++
+					}
+				}
+			}
+		}
+
+		row = append(row,
+			enclosedBy(m),
+			m.Pos.String(),
+			strconv.Itoa(numPaths),
+			strconv.FormatBool(pathLimited),
+			strconv.FormatBool(nodeLimited),
+			strconv.FormatBool(filterLimited),
+			strconv.Itoa(recoverable),
+			strings.Join(m.Auth, " -> "),
+			middlewareCell(m.Middleware),
+			strings.Join(inputStrings(m.Inputs), "; "),
+			strings.Join(responseStrings(m.Responses), "; "),
+			strings.Join(violationStrings(m), "; "),
+		)
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing record to CSV: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func writePathsTable(writer *csv.Writer, matches []match.RouteMatch) error {
+	header := []string{"match_id", "path", "path_id", "length", "node_limited", "filter_limited", "recoverable", "root", "nodes"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header to CSV: %v", err)
+	}
+
+	for _, m := range matches {
+		if m.SSA == nil || m.SSA.CallPaths == nil {
+			continue
+		}
+		for i, path := range m.SSA.CallPaths.Paths {
+			nodes := pathNodeStrings(path, m.SSA.TargetPos)
+			root := ""
+			if len(nodes) > 0 {
+				root = nodes[0]
+			}
+			row := []string{
+				m.MatchId,
+				strconv.Itoa(i + 1),
+				path.ID,
+				strconv.Itoa(len(path.Nodes)),
+				strconv.FormatBool(path.NodeLimited),
+				strconv.FormatBool(path.FilterLimited),
+				strconv.FormatBool(path.Recoverable),
+				root,
+				strings.Join(nodes, " -> "),
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("error writing record to CSV: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// pathNodeStrings returns the node strings of a path starting at the root and ending with the target
+func pathNodeStrings(path *match.CallPath, target string) []string {
+	var nodes []string
+	for x := len(path.Nodes) - 1; x >= 0; x-- {
+		nodes = append(nodes, path.Nodes[x].NodeString)
+	}
+	if target != "" {
+		nodes = append(nodes, target)
+	}
+	return nodes
+}
+
+func matchParamNames(matches []match.RouteMatch) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range matches {
+		for k := range m.Params {
+			if !seen[k] {
+				seen[k] = true
+				names = append(names, k)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func middlewareCell(chain []wallylib.Middleware) string {
+	if chain == nil {
+		return ""
+	}
+	return middlewareString(chain)
+}
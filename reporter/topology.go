@@ -0,0 +1,99 @@
+package reporter
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/hex0punk/wally/topology"
+	"strconv"
+	"strings"
+)
+
+var topologyGraphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "file", For: "node", AttrName: "file", AttrType: "string"},
+	{ID: "routes", For: "node", AttrName: "routes", AttrType: "int"},
+	{ID: "egress", For: "node", AttrName: "egress", AttrType: "int"},
+	{ID: "unmatched", For: "node", AttrName: "unmatched", AttrType: "int"},
+	{ID: "endpoints", For: "edge", AttrName: "endpoints", AttrType: "string"},
+	{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+}
+
+// PrintTopology writes the service topology graph as json, dot or graphml
+func PrintTopology(graph *topology.Graph, format string, filename string) error {
+	switch format {
+	case "", "json":
+		out, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutput(out, filename)
+	case "dot":
+		return writeOutput([]byte(buildTopologyDOT(graph)), filename)
+	case "graphml":
+		out, err := xml.MarshalIndent(buildTopologyGraphML(graph), "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutput(append([]byte(xml.Header), out...), filename)
+	default:
+		return fmt.Errorf("unsupported topology format %q. Supported: json, dot, graphml", format)
+	}
+}
+
+func buildTopologyDOT(graph *topology.Graph) string {
+	var sb strings.Builder
+	sb.WriteString("digraph topology {\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, s := range graph.Services {
+		sb.WriteString(fmt.Sprintf("  %s;\n", strconv.Quote(s.Name)))
+	}
+	for _, e := range graph.Edges {
+		sb.WriteString(fmt.Sprintf("  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To),
+			strconv.Quote(strings.Join(edgeEndpoints(e), "\n"))))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func buildTopologyGraphML(graph *topology.Graph) graphMLDoc {
+	doc := graphMLDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  topologyGraphMLKeys,
+		Graph: graphMLGraph{ID: "topology", EdgeDefault: "directed"},
+	}
+	for _, s := range graph.Services {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: s.Name,
+			Data: []graphMLData{
+				{Key: "label", Value: s.Name},
+				{Key: "file", Value: s.File},
+				{Key: "routes", Value: strconv.Itoa(s.Routes)},
+				{Key: "egress", Value: strconv.Itoa(s.Egress)},
+				{Key: "unmatched", Value: strconv.Itoa(s.Unmatched)},
+			},
+		})
+	}
+	for i, e := range graph.Edges {
+		endpoints := edgeEndpoints(e)
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: e.From,
+			Target: e.To,
+			Data: []graphMLData{
+				{Key: "endpoints", Value: strings.Join(endpoints, ", ")},
+				{Key: "weight", Value: strconv.Itoa(len(endpoints))},
+			},
+		})
+	}
+	return doc
+}
+
+// edgeEndpoints returns the distinct endpoints called on an edge
+func edgeEndpoints(e topology.Edge) []string {
+	var endpoints []string
+	for _, ep := range e.Endpoints {
+		endpoints = appendUnique(endpoints, ep.Endpoint)
+	}
+	return endpoints
+}
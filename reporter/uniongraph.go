@@ -0,0 +1,137 @@
+package reporter
+
+import (
+	"fmt"
+	"github.com/hex0punk/wally/match"
+	"github.com/hex0punk/wally/wallylib"
+	"strings"
+)
+
+// Edge kinds in the union graph
+const (
+	callEdge    = "call"
+	closureEdge = "closure"
+	matchEdge   = "match"
+)
+
+// unionGraph is the union of the call paths of all matches, where nodes
+// that appear in paths of different matches are represented only once
+type unionGraph struct {
+	Nodes []*unionNode
+	Edges []*unionEdge
+}
+
+type unionNode struct {
+	ID          string
+	Label       string
+	Package     string
+	Function    string
+	File        string
+	Line        int
+	Recoverable bool
+	IsMatch     bool
+	IsRoot      bool
+}
+
+type unionEdge struct {
+	ID       string
+	From     *unionNode
+	To       *unionNode
+	Kind     string
+	MatchIDs []string
+}
+
+func buildUnionGraph(matches []match.RouteMatch) *unionGraph {
+	g := &unionGraph{}
+	nodes := make(map[string]*unionNode)
+	edges := make(map[[2]string]*unionEdge)
+
+	getNode := func(key string, create func() *unionNode) *unionNode {
+		if n, ok := nodes[key]; ok {
+			return n
+		}
+		n := create()
+		n.ID = fmt.Sprintf("n%d", len(g.Nodes))
+		nodes[key] = n
+		g.Nodes = append(g.Nodes, n)
+		return n
+	}
+	addEdge := func(from, to *unionNode, kind, matchID string) {
+		key := [2]string{from.ID, to.ID}
+		e, ok := edges[key]
+		if !ok {
+			e = &unionEdge{ID: fmt.Sprintf("e%d", len(g.Edges)), From: from, To: to, Kind: kind}
+			edges[key] = e
+			g.Edges = append(g.Edges, e)
+		}
+		for _, id := range e.MatchIDs {
+			if id == matchID {
+				return
+			}
+		}
+		e.MatchIDs = append(e.MatchIDs, matchID)
+	}
+
+	for _, m := range matches {
+		if m.SSA == nil || m.SSA.CallPaths == nil {
+			continue
+		}
+
+		targetLabel := m.SSA.TargetPos
+		if targetLabel == "" {
+			targetLabel = fmt.Sprintf("%s.[%s] %s", m.Indicator.Package, m.Indicator.Function, m.Pos.String())
+		}
+		target := getNode(targetLabel, func() *unionNode {
+			return &unionNode{
+				Label:    targetLabel,
+				Package:  m.Indicator.Package,
+				Function: m.Indicator.Function,
+				File:     RelativeFilename(m.Pos.Filename),
+				Line:     m.Pos.Line,
+			}
+		})
+		target.IsMatch = true
+
+		for _, path := range m.SSA.CallPaths.Paths {
+			var prev *unionNode
+			// Nodes are stored from the match upwards, so we walk them backwards to start at the root of the path
+			for x := len(path.Nodes) - 1; x >= 0; x-- {
+				wn := path.Nodes[x]
+				n := getNode(wn.NodeString, func() *unionNode {
+					pos := wn.Position()
+					un := &unionNode{
+						Label: wn.NodeString,
+						File:  RelativeFilename(pos.Filename),
+						Line:  pos.Line,
+					}
+					if wn.Caller != nil && wn.Caller.Func != nil {
+						fn := wn.Caller.Func
+						un.Function = fn.Name()
+						if fn.Pkg != nil {
+							un.Package = fn.Pkg.Pkg.Path()
+							un.Function = fn.RelString(fn.Pkg.Pkg)
+						}
+					}
+					return un
+				})
+				n.Recoverable = n.Recoverable || wn.IsRecoverable() || strings.Contains(wn.NodeString, "(recoverable)")
+
+				if prev == nil {
+					n.IsRoot = true
+				} else {
+					kind := callEdge
+					if wn.Caller != nil && wn.Caller.Func != nil && wallylib.IsClosure(wn.Caller.Func) {
+						kind = closureEdge
+					}
+					addEdge(prev, n, kind, m.MatchId)
+				}
+				prev = n
+			}
+			if prev != nil {
+				addEdge(prev, target, matchEdge, m.MatchId)
+			}
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,344 @@
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hex0punk/wally/indicator"
+	"github.com/hex0punk/wally/match"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Match is a match as written by the json output format, with the fields used to link services
+type Match struct {
+	MatchId   string
+	Indicator indicator.Indicator
+	Kind      string
+	Params    map[string]string
+	Pos       string
+	Handler   string
+	Module    string
+}
+
+// Service holds the matches of one service, loaded from the json output of a wally run
+type Service struct {
+	Name string
+	File string
+	// Hosts are the host names the service is reached at, besides its name
+	Hosts   []string
+	Matches []Match
+}
+
+// Graph links the services calling each other, with the endpoints called on each edge
+type Graph struct {
+	Services []ServiceNode `json:"services"`
+	Edges    []Edge        `json:"edges"`
+	// Unmatched lists the outbound calls to an endpoint that no loaded service serves
+	Unmatched []Call `json:"unmatched,omitempty"`
+	// Ambiguous lists the outbound calls to an endpoint served by several services, none of which their host matches
+	Ambiguous []Call `json:"ambiguous,omitempty"`
+}
+
+// ServiceNode is a loaded service, with the number of its routes, outbound calls and calls not linked to a service
+type ServiceNode struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Routes    int    `json:"routes"`
+	Egress    int    `json:"egress"`
+	Unmatched int    `json:"unmatched"`
+}
+
+// Edge is a service calling another one
+type Edge struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is an endpoint of the server of an edge called by the client, as in /pkg.Svc/Method or GET /users/{id}
+type Endpoint struct {
+	Kind     string `json:"kind"`
+	Endpoint string `json:"endpoint"`
+	// Call is what the client calls, as in the URL of an HTTP request
+	Call        string `json:"call"`
+	ClientMatch string `json:"clientMatch"`
+	ClientPos   string `json:"clientPos"`
+	ServerMatch string `json:"serverMatch"`
+	Handler     string `json:"handler,omitempty"`
+}
+
+// Call is an outbound call of a service
+type Call struct {
+	Service string `json:"service"`
+	MatchId string `json:"matchId"`
+	Kind    string `json:"kind"`
+	Call    string `json:"call"`
+	Pos     string `json:"pos"`
+	// Candidates are the services serving the endpoint of an ambiguous call
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// route is an endpoint served by a service
+type route struct {
+	service string
+	match   Match
+	method  string
+	path    string
+}
+
+// call is an endpoint called by a service, with the method and host used if known
+type call struct {
+	service string
+	match   Match
+	method  string
+	host    string
+	path    string
+	target  string
+}
+
+// Load reads the matches of a service from a file written with the json output format. The argument is the path
+// of the file, optionally prefixed by the name of the service, as in orders=orders.json. The service is otherwise
+// named after the module most of its matches belong to, or after the file
+func Load(arg string) (*Service, error) {
+	name, path := "", arg
+	if i := strings.Index(arg, "="); i > 0 {
+		name, path = arg[:i], arg[i+1:]
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	if err := json.Unmarshal(data, &matches); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	if name == "" {
+		name = mainModule(matches)
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &Service{Name: name, File: path, Matches: matches}, nil
+}
+
+// Build links the outbound calls of each service to the routes of the other services. gRPC and other RPC calls are
+// linked by full method name, as in /pkg.Svc/Method, and HTTP calls by the path of their URL, with wildcards in
+// routes matching any segment. When routes of several services match, only those of the services the host of the
+// URL matches are kept, and calls still matching several services are reported as ambiguous rather than linked to
+// each. Calls of a service to its own routes are left out
+func Build(services []*Service) *Graph {
+	graph := &Graph{Services: []ServiceNode{}, Edges: []Edge{}}
+
+	var routes []route
+	var calls []call
+	for _, s := range services {
+		node := ServiceNode{Name: s.Name, File: s.File}
+		for _, m := range s.Matches {
+			if m.Indicator.Egress != "" {
+				node.Egress++
+				calls = append(calls, callsOf(s.Name, m)...)
+				continue
+			}
+			if m.Indicator.IndicatorType == indicator.Caller {
+				continue
+			}
+			rs := routesOf(s.Name, m)
+			if len(rs) > 0 {
+				node.Routes++
+			}
+			routes = append(routes, rs...)
+		}
+		graph.Services = append(graph.Services, node)
+	}
+
+	byName := make(map[string]*Service, len(services))
+	for _, s := range services {
+		byName[s.Name] = s
+	}
+
+	edges := make(map[[2]string]int)
+	unmatched := make(map[string]int)
+	for _, c := range calls {
+		var matched []route
+		for _, r := range routes {
+			if matchesRoute(c, r) {
+				matched = append(matched, r)
+			}
+		}
+		if c.host != "" {
+			var byHost []route
+			for _, r := range matched {
+				if byName[r.service].servesHost(c.host) {
+					byHost = append(byHost, r)
+				}
+			}
+			if len(byHost) > 0 {
+				matched = byHost
+			}
+		}
+
+		if candidates := routeServices(matched); len(candidates) > 1 {
+			graph.Ambiguous = append(graph.Ambiguous, Call{
+				Service:    c.service,
+				MatchId:    c.match.MatchId,
+				Kind:       c.match.Indicator.Egress,
+				Call:       strings.TrimSpace(c.method + " " + c.target),
+				Pos:        c.match.Pos,
+				Candidates: candidates,
+			})
+			continue
+		}
+
+		for _, r := range matched {
+			if r.service == c.service {
+				continue
+			}
+			key := [2]string{c.service, r.service}
+			i, ok := edges[key]
+			if !ok {
+				i = len(graph.Edges)
+				edges[key] = i
+				graph.Edges = append(graph.Edges, Edge{From: c.service, To: r.service})
+			}
+			graph.Edges[i].Endpoints = append(graph.Edges[i].Endpoints, Endpoint{
+				Kind:        r.match.Kind,
+				Endpoint:    strings.TrimSpace(r.method + " " + r.path),
+				Call:        strings.TrimSpace(c.method + " " + c.target),
+				ClientMatch: c.match.MatchId,
+				ClientPos:   c.match.Pos,
+				ServerMatch: r.match.MatchId,
+				Handler:     r.match.Handler,
+			})
+		}
+		if len(matched) == 0 {
+			unmatched[c.service]++
+			graph.Unmatched = append(graph.Unmatched, Call{
+				Service: c.service,
+				MatchId: c.match.MatchId,
+				Kind:    c.match.Indicator.Egress,
+				Call:    strings.TrimSpace(c.method + " " + c.target),
+				Pos:     c.match.Pos,
+			})
+		}
+	}
+	for i := range graph.Services {
+		graph.Services[i].Unmatched = unmatched[graph.Services[i].Name]
+	}
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph
+}
+
+// routeServices returns the names of the services serving routes, sorted
+func routeServices(routes []route) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range routes {
+		if !seen[r.service] {
+			seen[r.service] = true
+			names = append(names, r.service)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// servesHost reports whether host is one of the Hosts of the service, or is named after the service, as orders or
+// orders.default.svc.cluster.local are for a service named orders or example.com/orders
+func (s *Service) servesHost(host string) bool {
+	for _, h := range s.Hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	name := s.Name[strings.LastIndex(s.Name, "/")+1:]
+	label, _, _ := strings.Cut(host, ".")
+	return strings.EqualFold(host, s.Name) || strings.EqualFold(label, name)
+}
+
+// routesOf returns the endpoints served by a match: its HTTP routes, which include the full method names of RPC
+// methods as they start with a slash
+func routesOf(service string, m Match) []route {
+	rm := match.RouteMatch{Indicator: m.Indicator, Params: m.Params}
+	var routes []route
+	for _, r := range rm.HTTPRoutes() {
+		routes = append(routes, route{service: service, match: m, method: r.Method, path: r.Path})
+	}
+	return routes
+}
+
+// callsOf returns the endpoints called by an outbound call: the path of the URLs of HTTP calls, or the full method
+// name passed to gRPC clients. Calls to hosts rather than endpoints, such as grpc.Dial, are left out
+func callsOf(service string, m Match) []call {
+	rm := match.RouteMatch{Indicator: m.Indicator, Params: m.Params}
+	var method string
+	if methods := match.ParamValues(m.Params["method"]); m.Indicator.Egress == indicator.HTTPEgress && len(methods) == 1 {
+		method = strings.ToUpper(methods[0])
+	}
+
+	var calls []call
+	for _, target := range rm.Targets() {
+		path, host := target, ""
+		if !strings.HasPrefix(target, "/") {
+			u, err := url.Parse(target)
+			if err != nil || u.Host == "" || m.Indicator.Egress != indicator.HTTPEgress {
+				continue
+			}
+			path, host = u.Path, u.Hostname()
+		}
+		calls = append(calls, call{service: service, match: m, method: method, host: host, path: path, target: target})
+	}
+	return calls
+}
+
+// matchesRoute reports whether the path and method of c match route r. Segments such as {id} and :id match any
+// segment, and {path...} and *path match the rest of the path
+func matchesRoute(c call, r route) bool {
+	if c.method != "" && r.method != "" && c.method != r.method {
+		return false
+	}
+	callSegs := strings.Split(strings.Trim(c.path, "/"), "/")
+	routeSegs := strings.Split(strings.Trim(r.path, "/"), "/")
+	for i, seg := range routeSegs {
+		if strings.HasPrefix(seg, "*") || (strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}")) {
+			return true
+		}
+		if i >= len(callSegs) {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") || (strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")) {
+			continue
+		}
+		if seg != callSegs[i] {
+			return false
+		}
+	}
+	return len(callSegs) == len(routeSegs)
+}
+
+// mainModule returns the module most matches belong to
+func mainModule(matches []Match) string {
+	counts := make(map[string]int)
+	for _, m := range matches {
+		if m.Module != "" {
+			counts[m.Module]++
+		}
+	}
+	var main string
+	for module, n := range counts {
+		if n > counts[main] || (n == counts[main] && module < main) {
+			main = module
+		}
+	}
+	return main
+}
@@ -0,0 +1,264 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"strings"
+)
+
+// maxAuthDepth limits how many router and handler variables are followed back to their definitions
+const maxAuthDepth = 8
+
+// Methods that derive a router from another one while adding middleware, as in r.Group("/admin", mw) or r.With(mw)
+var routerDeriveMethods = map[string]bool{
+	"Group": true,
+	"With":  true,
+	"Route": true,
+}
+
+type authResolver struct {
+	pass       *analysis.Pass
+	decl       *ast.FuncDecl
+	middleware []string
+	pos        token.Pos
+	chain      []string
+	seen       map[string]bool
+}
+
+// ResolveAuthMiddleware returns the middleware functions from the given list that wrap the route registered by ce,
+// in the order they run. It looks at middleware applied to the router, via Use calls on the router before the
+// registration or via routers derived with Group or With, and then at the handler arguments of the call, which can
+// be built by calling middleware (auth.Middleware(h)) or be middleware themselves (r.GET("/", auth.Required(), h)).
+// Variables are followed to their last assignment before the call in the enclosing function decl. Returns an empty,
+// non nil slice if no middleware was found
+func ResolveAuthMiddleware(ce *ast.CallExpr, decl *ast.FuncDecl, middleware []string, pass *analysis.Pass) []string {
+	r := &authResolver{
+		pass:       pass,
+		decl:       decl,
+		middleware: middleware,
+		pos:        ce.Pos(),
+		chain:      []string{},
+		seen:       make(map[string]bool),
+	}
+
+	if sel, ok := ce.Fun.(*ast.SelectorExpr); ok {
+		r.routerMiddleware(sel.X, 0)
+	}
+	for _, arg := range ce.Args {
+		r.exprMiddleware(arg, 0)
+	}
+	return r.chain
+}
+
+// AuthFromChain returns the functions of a middleware chain resolved from SSA that are among the given auth
+// middleware, in the order they run. Returns an empty, non nil slice if none was found
+func AuthFromChain(chain []Middleware, middleware []string) []string {
+	auth := []string{}
+	for _, mw := range chain {
+		if mw.Func == nil {
+			continue
+		}
+		fn, ok := mw.Func.Object().(*types.Func)
+		if !ok {
+			continue
+		}
+		for _, name := range middleware {
+			if FuncNameMatches(fn, name) {
+				auth = append(auth, fn.FullName())
+				break
+			}
+		}
+	}
+	return auth
+}
+
+// routerMiddleware collects middleware applied to the router expression x
+func (r *authResolver) routerMiddleware(x ast.Expr, depth int) {
+	if depth > maxAuthDepth {
+		return
+	}
+
+	switch e := ast.Unparen(x).(type) {
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || !routerDeriveMethods[sel.Sel.Name] {
+			return
+		}
+		r.routerMiddleware(sel.X, depth+1)
+		for _, arg := range e.Args {
+			r.exprMiddleware(arg, depth+1)
+		}
+	case *ast.Ident, *ast.SelectorExpr:
+		// A router derived from another one, as in admin := r.Group("/admin"), inherits its middleware
+		if rhs := r.lastAssignment(e); rhs != nil {
+			r.routerMiddleware(rhs, depth+1)
+		}
+		for _, use := range r.useCalls(e) {
+			for _, arg := range use.Args {
+				r.exprMiddleware(arg, depth+1)
+			}
+		}
+	}
+}
+
+// exprMiddleware collects middleware referenced by a handler expression or an argument of a middleware call
+func (r *authResolver) exprMiddleware(x ast.Expr, depth int) {
+	if depth > maxAuthDepth {
+		return
+	}
+
+	switch e := ast.Unparen(x).(type) {
+	case *ast.CallExpr:
+		r.addIfMiddleware(e.Fun)
+		// Method calls on builders, as in alice.New(mw).Then(h), carry middleware in their receivers
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok {
+			if _, isCall := ast.Unparen(sel.X).(*ast.CallExpr); isCall {
+				r.exprMiddleware(sel.X, depth+1)
+			}
+		}
+		for _, arg := range e.Args {
+			r.exprMiddleware(arg, depth+1)
+		}
+	case *ast.Ident:
+		if r.addIfMiddleware(e) {
+			return
+		}
+		if rhs := r.lastAssignment(e); rhs != nil {
+			r.exprMiddleware(rhs, depth+1)
+		}
+	case *ast.SelectorExpr:
+		r.addIfMiddleware(e)
+	}
+}
+
+// addIfMiddleware adds the function referenced by expr to the chain if it is one of the configured middleware
+func (r *authResolver) addIfMiddleware(expr ast.Expr) bool {
+	fn := funcObj(expr, r.pass.TypesInfo)
+	if fn == nil {
+		return false
+	}
+	for _, name := range r.middleware {
+		if FuncNameMatches(fn, name) {
+			if !r.seen[fn.FullName()] {
+				r.seen[fn.FullName()] = true
+				r.chain = append(r.chain, fn.FullName())
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// useCalls returns the Use calls on the router x made in the enclosing function before the registration
+func (r *authResolver) useCalls(x ast.Expr) []*ast.CallExpr {
+	if r.decl == nil || r.decl.Body == nil {
+		return nil
+	}
+	var calls []*ast.CallExpr
+	ast.Inspect(r.decl.Body, func(node ast.Node) bool {
+		ce, ok := node.(*ast.CallExpr)
+		if !ok || ce.Pos() >= r.pos {
+			return true
+		}
+		if sel, ok := ce.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Use" && r.sameRouter(sel.X, x) {
+			calls = append(calls, ce)
+		}
+		return true
+	})
+	return calls
+}
+
+func (r *authResolver) sameRouter(a, b ast.Expr) bool {
+	ai, aOk := ast.Unparen(a).(*ast.Ident)
+	bi, bOk := ast.Unparen(b).(*ast.Ident)
+	if aOk && bOk {
+		obj := r.pass.TypesInfo.ObjectOf(ai)
+		return obj != nil && obj == r.pass.TypesInfo.ObjectOf(bi)
+	}
+	// Fields such as s.router are compared by expression
+	return types.ExprString(a) == types.ExprString(b)
+}
+
+// lastAssignment returns the value last assigned to the variable x in the enclosing function before the registration
+func (r *authResolver) lastAssignment(x ast.Expr) ast.Expr {
+	id, ok := ast.Unparen(x).(*ast.Ident)
+	if !ok || r.decl == nil || r.decl.Body == nil {
+		return nil
+	}
+	obj, ok := r.pass.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok {
+		return nil
+	}
+
+	var rhs ast.Expr
+	ast.Inspect(r.decl.Body, func(node ast.Node) bool {
+		if node == nil || node.Pos() >= r.pos {
+			return false
+		}
+		switch s := node.(type) {
+		case *ast.AssignStmt:
+			if len(s.Lhs) != len(s.Rhs) {
+				return true
+			}
+			for i, lhs := range s.Lhs {
+				if lid, ok := lhs.(*ast.Ident); ok && r.pass.TypesInfo.ObjectOf(lid) == obj {
+					rhs = s.Rhs[i]
+				}
+			}
+		case *ast.ValueSpec:
+			if len(s.Names) != len(s.Values) {
+				return true
+			}
+			for i, name := range s.Names {
+				if r.pass.TypesInfo.ObjectOf(name) == obj {
+					rhs = s.Values[i]
+				}
+			}
+		}
+		return true
+	})
+	return rhs
+}
+
+func funcObj(expr ast.Expr, info *types.Info) *types.Func {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		fn, _ := info.ObjectOf(e).(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			fn, _ := sel.Obj().(*types.Func)
+			return fn
+		}
+		fn, _ := info.ObjectOf(e.Sel).(*types.Func)
+		return fn
+	}
+	return nil
+}
+
+// FuncNameMatches compares a function to a name given either as its full name (github.com/org/repo/auth.Middleware),
+// a suffix of the full name after a slash (auth.Middleware), or its package name followed by the receiver type
+// name, if any, and the function name (jwt.Verifier.Verify)
+func FuncNameMatches(fn *types.Func, name string) bool {
+	full := fn.FullName()
+	if full == name || strings.HasSuffix(full, "/"+name) {
+		return true
+	}
+	if fn.Pkg() == nil {
+		return false
+	}
+
+	short := fn.Pkg().Name() + "." + fn.Name()
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		recv := sig.Recv().Type()
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		if named, ok := recv.(*types.Named); ok {
+			short = fn.Pkg().Name() + "." + named.Obj().Name() + "." + fn.Name()
+		}
+	}
+	return short == name
+}
@@ -69,6 +69,8 @@ type Options struct {
 	SkipClosures bool
 	ModuleOnly   bool
 	Simplify     bool
+	// EntryRoots ends call paths at functions run by CLI commands, scheduled jobs and workers, as it does at main
+	EntryRoots bool
 }
 
 func NewCallMapper(match *match.RouteMatch, nodes map[*ssa.Function]*callgraph.Node, options Options) *CallMapper {
@@ -88,8 +90,8 @@ func NewCallMapper(match *match.RouteMatch, nodes map[*ssa.Function]*callgraph.N
 func (cm *CallMapper) initPath(s *callgraph.Node) []wallynode.WallyNode {
 	encPkg := cm.Match.SSA.EnclosedByFunc.Pkg
 	encBasePos := wallylib.GetFormattedPos(encPkg, cm.Match.SSA.EnclosedByFunc.Pos())
-	rec := wallynode.IsRecoverable(s, cm.CallgraphNodes)
-	encStr := wallynode.GetNodeString(encBasePos, s, rec)
+	rec := cm.NodeFactory.IsRecoverable(s)
+	encStr := cm.NodeFactory.NodeString(encBasePos, s, rec)
 
 	//if cm.Options.Simplify {
 	//	cm.Match.SSA.TargetPos = encStr
@@ -109,8 +111,8 @@ func (cm *CallMapper) initPath(s *callgraph.Node) []wallynode.WallyNode {
 			siteStr = fmt.Sprintf("%s.[%s] %s", sitePkg.Pkg.Name(), cm.Match.Indicator.Function, siteBasePos)
 		} else {
 			targetFuncNode := cm.CallgraphNodes[cm.Match.SSA.SSAFunc]
-			isRec := wallynode.IsRecoverable(targetFuncNode, cm.CallgraphNodes)
-			siteStr = wallynode.GetNodeString(siteBasePos, targetFuncNode, isRec)
+			isRec := cm.NodeFactory.IsRecoverable(targetFuncNode)
+			siteStr = cm.NodeFactory.NodeString(siteBasePos, targetFuncNode, isRec)
 		}
 		cm.Match.SSA.TargetPos = siteStr
 	}
@@ -147,7 +149,7 @@ func (cm *CallMapper) DFS(destination *callgraph.Node, visited map[int]bool, pat
 	}
 	newPath := cm.appendNodeToPath(destination, path, site)
 
-	if cm.Options.Limiter > None && isMainFunc(destination) {
+	if cm.Options.Limiter > None && isMainFunc(destination) || cm.isEntryRoot(destination) {
 		paths.InsertPaths(newPath, false, false, cm.Options.Simplify)
 		cm.Stop = false
 		return
@@ -232,7 +234,7 @@ func (cm *CallMapper) BFS(start *callgraph.Node, initialPath []wallynode.WallyNo
 			continue
 		}
 
-		if cm.Options.Limiter > None && isMainFunc(currentNode) {
+		if cm.Options.Limiter > None && isMainFunc(currentNode) || cm.isEntryRoot(currentNode) {
 			paths.InsertPaths(currentPath, false, false, cm.Options.Simplify)
 			continue
 		}
@@ -326,6 +328,11 @@ func limitFuncsReached(path []wallynode.WallyNode, options Options) bool {
 	return options.MaxFuncs > 0 && len(path) >= options.MaxFuncs
 }
 
+// isEntryRoot reports whether call paths end at node because its function is run by an entry point other than a route
+func (cm *CallMapper) isEntryRoot(node *callgraph.Node) bool {
+	return cm.Options.EntryRoots && node.Func != nil && cm.NodeFactory.EntryFuncs[node.Func] != ""
+}
+
 func isMainFunc(node *callgraph.Node) bool {
 	return node.Func.Name() == "main" || strings.HasPrefix(node.Func.Name(), "main$")
 }
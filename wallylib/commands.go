@@ -0,0 +1,482 @@
+package wallylib
+
+import (
+	"github.com/hex0punk/wally/indicator"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	cobraPkg = "github.com/spf13/cobra"
+	pflagPkg = "github.com/spf13/pflag"
+	flagPkg  = "flag"
+)
+
+// Methods of cobra.Command returning the flag sets of the command
+var cobraFlagSets = map[string]bool{
+	"Flags":                   true,
+	"PersistentFlags":         true,
+	"LocalFlags":              true,
+	"LocalNonPersistentFlags": true,
+	"InheritedFlags":          true,
+}
+
+// Command is a CLI command declared by a command struct literal, as in &cobra.Command{Use: "serve"}, or by a call
+// parsing the flags of a flag set, as in fs.Parse(args)
+type Command struct {
+	Framework string
+	// Site is the *ast.CompositeLit or *ast.CallExpr declaring the command
+	Site ast.Node
+	Pass *analysis.Pass
+	// Handler is the expression of the function run by the command, if set in the command literal
+	Handler ast.Expr
+	// Name is the name the command is invoked with
+	Name string
+	// Parent is the index of the parent command, or -1 for root commands
+	Parent int
+	// Flags lists the flags defined or read for the command, as in --config, along with the persistent flags of the
+	// parents of cobra commands
+	Flags []string
+}
+
+// Path returns the names of the command and its parents, from the root command, as in wally map search
+func (c *Command) Path(commands []*Command) string {
+	names := []string{c.Name}
+	for p, depth := c.Parent, 0; p >= 0 && depth < len(commands); p, depth = commands[p].Parent, depth+1 {
+		names = append([]string{commands[p].Name}, names...)
+	}
+	return strings.Join(names, " ")
+}
+
+// flagPkgKey identifies the command of the flags defined with the functions of the flag package in a package
+type flagPkgKey struct {
+	pkg *types.Package
+}
+
+type commandResolver struct {
+	commands []*Command
+	// keys maps the variables, fields and functions holding or returning each command, along with their literals
+	// and the command parameter of their handlers, to the index of the command
+	keys map[interface{}]int
+	// handlerFuncs maps functions used as handlers to their command, so that their command parameter is known
+	handlerFuncs map[*types.Func]int
+	// flagSets maps variables holding flag sets created with flag.NewFlagSet to the name of the set
+	flagSets    map[types.Object]string
+	flagSetVars map[int]types.Object
+	flags       []map[string]bool
+	// persistent holds the persistent flags of cobra commands, which are inherited by their subcommands
+	persistent []map[string]bool
+}
+
+// ResolveCommands sets the name, parent and flags of commands, reading the calls adding commands to their parents and
+// defining or reading flags in passes. cobra commands are added to their parent with AddCommand, urfave/cli commands
+// are nested in the Commands or Subcommands of their parent, and flag sets created with flag.NewFlagSet are children
+// of the program command parsing the flags of the flag package, if any
+func ResolveCommands(commands []*Command, passes []*analysis.Pass) {
+	r := &commandResolver{
+		commands:     commands,
+		keys:         make(map[interface{}]int),
+		handlerFuncs: make(map[*types.Func]int),
+		flagSets:     make(map[types.Object]string),
+		flagSetVars:  make(map[int]types.Object),
+		flags:        make([]map[string]bool, len(commands)),
+		persistent:   make([]map[string]bool, len(commands)),
+	}
+
+	for i, c := range commands {
+		c.Parent = -1
+		r.flags[i] = make(map[string]bool)
+		r.persistent[i] = make(map[string]bool)
+		r.addKeys(i)
+	}
+	// Subcommands are listed in their parent, either as literals or created elsewhere, as in
+	// Commands: []*cli.Command{{Name: "serve"}, newMigrateCmd()}
+	for i, c := range commands {
+		lit, ok := c.Site.(*ast.CompositeLit)
+		if !ok || c.Framework != indicator.UrfaveCLIFramework {
+			continue
+		}
+		for _, field := range []string{"Commands", "Subcommands"} {
+			list, ok := LiteralField(lit, field).(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			for _, elt := range list.Elts {
+				if child := r.lookup(elt, c.Pass.TypesInfo); child >= 0 && child != i && commands[child].Parent < 0 {
+					commands[child].Parent = i
+				}
+			}
+		}
+	}
+
+	for _, pass := range passes {
+		pass := pass
+		for _, file := range pass.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				r.visit(node, pass)
+				return true
+			})
+		}
+	}
+
+	for i, c := range commands {
+		for p, depth := c.Parent, 0; p >= 0 && depth < len(commands); p, depth = commands[p].Parent, depth+1 {
+			for f := range r.persistent[p] {
+				r.flags[i][f] = true
+			}
+		}
+	}
+
+	programs := make(map[*types.Package]int)
+	for k, i := range r.keys {
+		if key, ok := k.(flagPkgKey); ok {
+			programs[key.pkg] = i
+		}
+	}
+	for i, c := range commands {
+		if v, ok := r.flagSetVars[i]; ok {
+			c.Name = r.flagSets[v]
+			if p, ok := programs[c.Pass.Pkg]; ok && p != i {
+				c.Parent = p
+			}
+		}
+		c.Flags = make([]string, 0, len(r.flags[i]))
+		for f := range r.flags[i] {
+			c.Flags = append(c.Flags, f)
+		}
+		sort.Strings(c.Flags)
+	}
+}
+
+// addKeys records what holds command i
+func (r *commandResolver) addKeys(i int) {
+	c := r.commands[i]
+	info := c.Pass.TypesInfo
+
+	if ce, ok := c.Site.(*ast.CallExpr); ok {
+		// Flags of the flag package are parsed either with flag.Parse or on a flag set, as in fs.Parse(args)
+		if sel, ok := ast.Unparen(ce.Fun).(*ast.SelectorExpr); ok && info.Selections[sel] != nil {
+			if obj := exprKey(sel.X, info); obj != nil {
+				r.keys[obj] = i
+				if v, ok := obj.(types.Object); ok {
+					r.flagSetVars[i] = v
+				}
+			}
+			return
+		}
+		r.keys[flagPkgKey{pkg: c.Pass.Pkg}] = i
+		c.Name = programName(c.Pass.Pkg)
+		return
+	}
+
+	lit, ok := c.Site.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	r.keys[lit] = i
+	switch c.Framework {
+	case indicator.CobraFramework:
+		if use := strings.Fields(constString(LiteralField(lit, "Use"), info)); len(use) > 0 {
+			c.Name = use[0]
+		}
+	case indicator.UrfaveCLIFramework:
+		c.Name = constString(LiteralField(lit, "Name"), info)
+		r.addLiteralFlags(i, LiteralField(lit, "Flags"), info)
+	}
+
+	switch h := c.Handler.(type) {
+	case *ast.FuncLit:
+		if params := h.Type.Params.List; len(params) > 0 && len(params[0].Names) > 0 {
+			if obj := info.Defs[params[0].Names[0]]; obj != nil {
+				r.keys[obj] = i
+			}
+		}
+	case nil:
+	default:
+		if fn, ok := exprKey(h, info).(*types.Func); ok {
+			r.handlerFuncs[fn] = i
+		}
+	}
+
+	if file := fileOf(c.Pass, lit.Pos()); file != nil {
+		nodes, _ := astutil.PathEnclosingInterval(file, lit.Pos(), lit.End())
+		r.addHolderKeys(i, lit, nodes, info)
+	}
+}
+
+// addHolderKeys records the variable or field a command literal is assigned to, and the function returning it
+func (r *commandResolver) addHolderKeys(i int, lit *ast.CompositeLit, nodes []ast.Node, info *types.Info) {
+	var expr ast.Expr = lit
+	for j, node := range nodes[1:] {
+		switch n := node.(type) {
+		case *ast.ParenExpr, *ast.UnaryExpr:
+			expr = n.(ast.Expr)
+			continue
+		case *ast.ValueSpec:
+			for k, v := range n.Values {
+				if v == expr && k < len(n.Names) {
+					r.addVarKey(i, info.Defs[n.Names[k]], nodes[j+1:], info)
+				}
+			}
+		case *ast.AssignStmt:
+			for k, v := range n.Rhs {
+				if v == expr && k < len(n.Lhs) {
+					r.addVarKey(i, exprKey(n.Lhs[k], info), nodes[j+1:], info)
+				}
+			}
+		case *ast.ReturnStmt:
+			if decl := enclosingDecl(nodes[j+1:]); decl != nil {
+				if fn, ok := info.Defs[decl.Name].(*types.Func); ok {
+					r.keys[fn] = i
+				}
+			}
+		}
+		return
+	}
+}
+
+// addVarKey records the variable v holding command i, along with the enclosing function if it returns v
+func (r *commandResolver) addVarKey(i int, v interface{}, nodes []ast.Node, info *types.Info) {
+	if v == nil {
+		return
+	}
+	r.keys[v] = i
+
+	decl := enclosingDecl(nodes)
+	if decl == nil || decl.Body == nil {
+		return
+	}
+	fn, ok := info.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return
+	}
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) == 1 && exprKey(n.Results[0], info) == v {
+				r.keys[fn] = i
+			}
+		}
+		return true
+	})
+}
+
+// addLiteralFlags records the names of the flag literals listed in the Flags of an urfave/cli command
+func (r *commandResolver) addLiteralFlags(i int, list ast.Expr, info *types.Info) {
+	lit, ok := list.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range lit.Elts {
+		elt = ast.Unparen(elt)
+		if u, ok := elt.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			elt = ast.Unparen(u.X)
+		}
+		if flag, ok := elt.(*ast.CompositeLit); ok {
+			if name := constString(LiteralField(flag, "Name"), info); name != "" {
+				r.flags[i]["--"+name] = true
+			}
+		}
+	}
+}
+
+func (r *commandResolver) visit(node ast.Node, pass *analysis.Pass) {
+	info := pass.TypesInfo
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		// The first parameter of cobra and urfave/cli handlers is the command, or the context of the command
+		fn, ok := info.Defs[n.Name].(*types.Func)
+		if !ok {
+			return
+		}
+		if i, ok := r.handlerFuncs[fn]; ok && len(n.Type.Params.List) > 0 && len(n.Type.Params.List[0].Names) > 0 {
+			if obj := info.Defs[n.Type.Params.List[0].Names[0]]; obj != nil {
+				r.keys[obj] = i
+			}
+		}
+	case *ast.ValueSpec:
+		for k, v := range n.Values {
+			if name, ok := newFlagSetName(v, info); ok && k < len(n.Names) {
+				r.flagSets[info.Defs[n.Names[k]]] = name
+			}
+		}
+	case *ast.AssignStmt:
+		for k, v := range n.Rhs {
+			if name, ok := newFlagSetName(v, info); ok && k < len(n.Lhs) {
+				if obj, ok := exprKey(n.Lhs[k], info).(types.Object); ok {
+					r.flagSets[obj] = name
+				}
+			}
+		}
+	case *ast.CallExpr:
+		r.visitCall(n, pass)
+	}
+}
+
+func (r *commandResolver) visitCall(ce *ast.CallExpr, pass *analysis.Pass) {
+	info := pass.TypesInfo
+	fn := funcObj(ce.Fun, info)
+	if fn == nil || fn.Pkg() == nil {
+		return
+	}
+	sig := fn.Type().(*types.Signature)
+	sel, _ := ast.Unparen(ce.Fun).(*ast.SelectorExpr)
+
+	var recv types.Type
+	if sig.Recv() != nil {
+		recv = sig.Recv().Type()
+	}
+
+	switch {
+	case fn.Name() == "AddCommand" && isNamed(recv, cobraPkg, "Command") && sel != nil:
+		parent := r.lookup(sel.X, info)
+		if parent < 0 {
+			return
+		}
+		for _, arg := range ce.Args {
+			if child := r.lookup(arg, info); child >= 0 && child != parent && r.commands[child].Parent < 0 {
+				r.commands[child].Parent = parent
+			}
+		}
+	case isNamed(recv, pflagPkg, "FlagSet") && sel != nil:
+		// Flags of cobra commands are defined or read on the flag sets of the command, as in
+		// cmd.Flags().GetString("config")
+		flagSet, ok := ast.Unparen(sel.X).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		flagSetSel, ok := ast.Unparen(flagSet.Fun).(*ast.SelectorExpr)
+		if !ok || !cobraFlagSets[flagSetSel.Sel.Name] {
+			return
+		}
+		i := r.lookup(flagSetSel.X, info)
+		if name := r.addFlag(i, "--", ce, sig, info); name != "" && flagSetSel.Sel.Name == "PersistentFlags" {
+			r.persistent[i][name] = true
+		}
+	case fn.Pkg().Path() == flagPkg && fn.Name() != "NewFlagSet":
+		if recv == nil {
+			if i, ok := r.keys[flagPkgKey{pkg: pass.Pkg}]; ok {
+				r.addFlag(i, "-", ce, sig, info)
+			}
+		} else if isNamed(recv, flagPkg, "FlagSet") && sel != nil {
+			r.addFlag(r.lookup(sel.X, info), "-", ce, sig, info)
+		}
+	}
+}
+
+// addFlag records the flag named by the name argument of ce, a call defining or reading a flag, for command i. Returns
+// the flag recorded, if any
+func (r *commandResolver) addFlag(i int, prefix string, ce *ast.CallExpr, sig *types.Signature, info *types.Info) string {
+	if i < 0 {
+		return ""
+	}
+	pos, err := GetParamPos(sig, "name")
+	if err != nil || pos >= len(ce.Args) {
+		return ""
+	}
+	name := constString(ce.Args[pos], info)
+	if name == "" {
+		return ""
+	}
+	r.flags[i][prefix+name] = true
+	return prefix + name
+}
+
+func (r *commandResolver) lookup(expr ast.Expr, info *types.Info) int {
+	key := exprKey(expr, info)
+	if key == nil {
+		return -1
+	}
+	if i, ok := r.keys[key]; ok {
+		return i
+	}
+	return -1
+}
+
+// exprKey returns what an expression referring to a command refers to: a variable or field, the function called
+// to create it, or the command literal itself
+func exprKey(expr ast.Expr, info *types.Info) interface{} {
+	expr = ast.Unparen(expr)
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = ast.Unparen(u.X)
+	}
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return e
+	case *ast.Ident:
+		if obj := info.ObjectOf(e); obj != nil {
+			return obj
+		}
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			return sel.Obj()
+		}
+		if obj := info.Uses[e.Sel]; obj != nil {
+			return obj
+		}
+	case *ast.CallExpr:
+		if fn := funcObj(e.Fun, info); fn != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// newFlagSetName returns the name of the flag set created by expr, if it is a call to flag.NewFlagSet
+func newFlagSetName(expr ast.Expr, info *types.Info) (string, bool) {
+	ce, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(ce.Args) == 0 {
+		return "", false
+	}
+	fn := funcObj(ce.Fun, info)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != flagPkg || fn.Name() != "NewFlagSet" {
+		return "", false
+	}
+	return constString(ce.Args[0], info), true
+}
+
+// programName returns the name of the binary built from pkg, as in wally for github.com/hex0punk/wally
+func programName(pkg *types.Package) string {
+	return path.Base(unversionedPath(pkg.Path()))
+}
+
+func constString(expr ast.Expr, info *types.Info) string {
+	if expr == nil {
+		return ""
+	}
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return ""
+	}
+	return constant.StringVal(tv.Value)
+}
+
+func enclosingDecl(nodes []ast.Node) *ast.FuncDecl {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return nil
+		case *ast.FuncDecl:
+			return n
+		}
+	}
+	return nil
+}
+
+func fileOf(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
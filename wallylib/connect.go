@@ -0,0 +1,72 @@
+package wallylib
+
+import (
+	"go/types"
+	"strings"
+)
+
+var connectPkgs = []string{"connectrpc.com/connect", "github.com/bufbuild/connect-go"}
+
+// isConnectConstructor reports whether a function is a generated NewXxxHandler function, which takes the
+// implementation of a service and returns the path prefix of the service along with its handler
+func isConnectConstructor(name string, sig *types.Signature) bool {
+	if !strings.HasPrefix(name, "New") || !strings.HasSuffix(name, "Handler") || !sig.Variadic() {
+		return false
+	}
+	params, results := sig.Params(), sig.Results()
+	if params.Len() != 2 || results.Len() != 2 || !types.IsInterface(params.At(0).Type()) {
+		return false
+	}
+	if basic, ok := results.At(0).Type().(*types.Basic); !ok || basic.Kind() != types.String {
+		return false
+	}
+	opts, ok := params.At(1).Type().(*types.Slice)
+	return ok && isConnectType(opts.Elem(), "HandlerOption") && isNamed(results.At(1).Type(), "net/http", "Handler")
+}
+
+// resolveConnectMethods returns the procedures of the service constructed by fn. Procedures are read from the
+// constants generated for them, as in GreetServiceGreetProcedure, or derived from the name of the service
+func resolveConnectMethods(fn *types.Func, impl types.Type) []RPCMethod {
+	svc := serviceName(fn.Name(), "New", "Handler")
+	iface := fn.Type().(*types.Signature).Params().At(0).Type()
+	fullName, _ := stringConst(fn.Pkg(), svc+"Name")
+
+	var res []RPCMethod
+	for _, m := range interfaceMethods(iface) {
+		procedure, ok := stringConst(fn.Pkg(), svc+m.Name()+"Procedure")
+		if !ok {
+			if fullName == "" {
+				continue
+			}
+			procedure = "/" + fullName + "/" + m.Name()
+		}
+		service := fullName
+		if service == "" {
+			service, _, _ = strings.Cut(strings.TrimPrefix(procedure, "/"), "/")
+		}
+		res = append(res, RPCMethod{
+			FullMethod: procedure,
+			Service:    service,
+			Method:     m.Name(),
+			Streaming:  !isConnectUnary(m),
+			Impl:       lookupImpl(impl, iface, m.Name()),
+		})
+	}
+	return res
+}
+
+// isConnectUnary reports whether a method of a Connect service handler takes a *connect.Request, as opposed to a
+// stream
+func isConnectUnary(m *types.Func) bool {
+	sig := m.Type().(*types.Signature)
+	return sig.Params().Len() == 2 && isConnectType(sig.Params().At(1).Type(), "Request")
+}
+
+func isConnectType(t types.Type, name string) bool {
+	for _, pkg := range connectPkgs {
+		if isNamed(t, pkg, name) {
+			return true
+		}
+	}
+	return false
+}
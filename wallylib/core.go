@@ -10,6 +10,7 @@ import (
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
+	"path"
 	"strings"
 )
 
@@ -39,26 +40,49 @@ type SSAContext struct {
 }
 
 func (fi *FuncInfo) Match(indicators []indicator.Indicator) *indicator.Indicator {
+	return fi.match(indicators, false)
+}
+
+// MatchGo returns the indicator matching go statements in the function fi, as in go worker.Run(ctx) in main
+func (fi *FuncInfo) MatchGo(indicators []indicator.Indicator) *indicator.Indicator {
+	return fi.match(indicators, true)
+}
+
+func (fi *FuncInfo) match(indicators []indicator.Indicator, goStmt bool) *indicator.Indicator {
 	var match *indicator.Indicator
 
 	for _, ind := range indicators {
 		ind := ind
+		if ind.Go != goStmt {
+			continue
+		}
 
 		// User may decide they do not care if the package matches.
-		// It'd be worth adding a command to "take a guess" for potential routes
+		// wally guess proposes indicators for potential routes when the package is not known
 		if fi.Package != ind.Package && ind.Package != "*" {
 			continue
 		}
-		if fi.Name != ind.Function {
+		if !matchFuncName(fi.Name, ind.Function) {
+			continue
+		}
+		if ind.Framework != "" && ind.Package == "*" && !IsRPCConstructor(ind.Framework, fi.Name, fi.Signature) {
 			continue
 		}
 
-		if ind.ReceiverType != "" {
+		if ind.ReceiverType == indicator.NoReceiver {
+			if fi.Signature == nil || fi.Signature.Recv() != nil {
+				continue
+			}
+		} else if ind.ReceiverType != "" {
 			if !fi.matchReceiver(ind.Package, ind.ReceiverType) {
 				continue
 			}
 		}
 
+		if ind.SQL && !hasStatementParam(fi.Signature, ind.Params) {
+			continue
+		}
+
 		filterMatch := false
 		if len(ind.MatchFilters) > 0 {
 			for _, mf := range ind.MatchFilters {
@@ -79,6 +103,60 @@ func (fi *FuncInfo) Match(indicators []indicator.Indicator) *indicator.Indicator
 	return match
 }
 
+// MatchLiteral returns the indicator matching a composite literal of type t found in pkg, if any. Only indicators
+// with a Struct match literals
+func MatchLiteral(t types.Type, pkg *types.Package, indicators []indicator.Indicator) *indicator.Indicator {
+	// Elements of slices of pointers may elide &T, as in []*cli.Command{{Name: "serve"}}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil
+	}
+
+	var match *indicator.Indicator
+	for _, ind := range indicators {
+		ind := ind
+		if ind.Struct == "" || ind.Struct != named.Obj().Name() || ind.Package != named.Obj().Pkg().Path() {
+			continue
+		}
+		if len(ind.MatchFilters) > 0 {
+			filterMatch := false
+			for _, mf := range ind.MatchFilters {
+				if mf != "" && strings.HasPrefix(pkg.Path(), mf) {
+					filterMatch = true
+					break
+				}
+			}
+			if !filterMatch {
+				continue
+			}
+		}
+		match = &ind
+	}
+	return match
+}
+
+// matchFuncName reports whether name matches the function of an indicator, which may contain "*" wildcards
+func matchFuncName(name, pattern string) bool {
+	if !strings.Contains(pattern, "*") {
+		return name == pattern
+	}
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+// hasStatementParam reports whether sig has the param holding the statement of a SQL indicator, so that functions of
+// the same name taking prepared statements, as in Stmt.Query, are not matched
+func hasStatementParam(sig *types.Signature, params []indicator.RouteParam) bool {
+	if sig == nil || len(params) == 0 {
+		return false
+	}
+	_, err := GetParamPos(sig, params[0].Name)
+	return err == nil
+}
+
 func (fi *FuncInfo) matchReceiver(pkg, recvType string) bool {
 	if fi.Signature == nil || fi.Signature.Recv() == nil {
 		return false
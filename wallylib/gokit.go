@@ -0,0 +1,166 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+)
+
+const goKitEndpointPkg = "github.com/go-kit/kit/endpoint"
+
+// go-kit transports that serve an endpoint, by function full name. The endpoint is the first argument
+var goKitServers = map[string]bool{
+	"github.com/go-kit/kit/transport/http.NewServer": true,
+	"github.com/go-kit/kit/transport/grpc.NewServer": true,
+}
+
+// ResolveGoKitHandler returns the service method called by the endpoint of the go-kit server created by ce, or passed
+// as the handler of the route registered by ce, as in r.Handle("/sum", kithttp.NewServer(makeSumEndpoint(svc), ...)).
+// Endpoint factories found with source are followed, and the method is resolved on the concrete type of the service
+// passed to them. The name of the method is returned along with the method. Returns a description of the endpoint and
+// a nil method if no method was found, and an empty string if ce involves no go-kit server
+func ResolveGoKitHandler(ce *ast.CallExpr, pass *analysis.Pass, source FuncSource) (string, *types.Func) {
+	server := ce
+	if !isGoKitServer(server, pass) {
+		server = nil
+		for i := len(ce.Args) - 1; i >= 0; i-- {
+			if isHandlerType(pass.TypesInfo.TypeOf(ce.Args[i])) {
+				server, _ = ast.Unparen(ce.Args[i]).(*ast.CallExpr)
+				break
+			}
+		}
+		if server == nil || !isGoKitServer(server, pass) {
+			return "", nil
+		}
+	}
+	if len(server.Args) == 0 {
+		return "", nil
+	}
+
+	if m := endpointMethod(server.Args[0], pass, source, 0); m != nil {
+		return m.FullName(), m
+	}
+	return DescribeHandlerExpr(server.Args[0], pass), nil
+}
+
+func isGoKitServer(ce *ast.CallExpr, pass *analysis.Pass) bool {
+	fn := funcObj(ce.Fun, pass.TypesInfo)
+	return fn != nil && goKitServers[fn.FullName()]
+}
+
+// endpointMethod returns the service method called by an endpoint expression: a function literal, a call to an
+// endpoint factory such as makeSumEndpoint(svc), or middleware wrapping either of them
+func endpointMethod(expr ast.Expr, pass *analysis.Pass, source FuncSource, depth int) *types.Func {
+	if depth > maxHandlerDepth {
+		return nil
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		// Services used by literals are captured from the enclosing function
+		m, _ := serviceCall(e.Body, pass, func(obj types.Object) bool {
+			return obj.Pos() < e.Pos() || obj.Pos() > e.End()
+		})
+		return m
+	case *ast.CallExpr:
+		if fn := funcObj(e.Fun, pass.TypesInfo); fn != nil {
+			if decl, declPass := source(fn); decl != nil {
+				if m := factoryMethod(decl, declPass, e, pass); m != nil {
+					return m
+				}
+			}
+		}
+		// Middleware wrapping an endpoint, as in mw(makeSumEndpoint(svc)) or endpoint.Chain(mw)(ep)
+		for _, arg := range e.Args {
+			if isNamed(pass.TypesInfo.TypeOf(arg), goKitEndpointPkg, "Endpoint") {
+				if m := endpointMethod(arg, pass, source, depth+1); m != nil {
+					return m
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// factoryMethod returns the service method called by the endpoint returned by an endpoint factory, resolved on the
+// concrete type of the argument passed for the service in call
+func factoryMethod(decl *ast.FuncDecl, declPass *analysis.Pass, call *ast.CallExpr, pass *analysis.Pass) *types.Func {
+	if decl.Body == nil || decl.Type.Params == nil {
+		return nil
+	}
+	params := make(map[types.Object]int)
+	idx := 0
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			params[declPass.TypesInfo.Defs[name]] = idx
+			idx++
+		}
+		if len(field.Names) == 0 {
+			idx++
+		}
+	}
+
+	var res *types.Func
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		ret, ok := node.(*ast.ReturnStmt)
+		if !ok || res != nil {
+			return res == nil
+		}
+		for _, result := range ret.Results {
+			lit, ok := ast.Unparen(result).(*ast.FuncLit)
+			if !ok {
+				continue
+			}
+			m, recv := serviceCall(lit.Body, declPass, func(obj types.Object) bool {
+				_, isParam := params[obj]
+				return isParam
+			})
+			if m == nil {
+				continue
+			}
+			res = m
+			if i := params[recv]; i < len(call.Args) {
+				if impl := lookupImpl(pass.TypesInfo.TypeOf(call.Args[i]), m.Type().(*types.Signature).Recv().Type(), m.Name()); impl != nil {
+					res = impl
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return res
+}
+
+// serviceCall returns the first method called in body on a variable accepted by isService, along with the variable
+func serviceCall(body *ast.BlockStmt, pass *analysis.Pass, isService func(obj types.Object) bool) (*types.Func, types.Object) {
+	var method *types.Func
+	var recv types.Object
+	ast.Inspect(body, func(node ast.Node) bool {
+		if method != nil {
+			return false
+		}
+		ce, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := ast.Unparen(ce.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := ast.Unparen(sel.X).(*ast.Ident)
+		if !ok {
+			return true
+		}
+		selection, ok := pass.TypesInfo.Selections[sel]
+		if !ok || selection.Kind() != types.MethodVal {
+			return true
+		}
+		obj := pass.TypesInfo.Uses[ident]
+		if v, ok := obj.(*types.Var); ok && isService(v) {
+			method, _ = selection.Obj().(*types.Func)
+			recv = obj
+		}
+		return true
+	})
+	return method, recv
+}
@@ -0,0 +1,102 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"unicode"
+	"unicode/utf8"
+)
+
+// resolveGraphQLResolvers returns the resolver methods of the schema served by ce, a call to a gqlgen handler
+// constructor, as in handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: r})). The root
+// resolver r has a method per GraphQL type returning the resolver interface of its fields, as in Query() QueryResolver.
+// Fields are resolved on the type returned by these methods, read with source
+func resolveGraphQLResolvers(ce *ast.CallExpr, pass *analysis.Pass, source PackageSource) []RPCMethod {
+	root := resolversExpr(ce)
+	if root == nil {
+		return nil
+	}
+	rootType := pass.TypesInfo.TypeOf(root)
+	if rootType == nil {
+		return nil
+	}
+	if _, ok := rootType.Underlying().(*types.Interface); !ok {
+		if _, ok := rootType.(*types.Pointer); !ok {
+			rootType = types.NewPointer(rootType)
+		}
+	}
+
+	var res []RPCMethod
+	mset := types.NewMethodSet(rootType)
+	for i := 0; i < mset.Len(); i++ {
+		m, ok := mset.At(i).Obj().(*types.Func)
+		if !ok || !m.Exported() {
+			continue
+		}
+		sig := m.Type().(*types.Signature)
+		if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+			continue
+		}
+		iface := sig.Results().At(0).Type()
+		fields := interfaceMethods(iface)
+		if len(fields) == 0 {
+			continue
+		}
+
+		impl := returnedType(m, source)
+		for _, field := range fields {
+			name := lowerFirst(field.Name())
+			res = append(res, RPCMethod{
+				FullMethod: m.Name() + "." + name,
+				Service:    m.Name(),
+				Method:     name,
+				Impl:       lookupImpl(impl, iface, field.Name()),
+			})
+		}
+	}
+	return res
+}
+
+// resolversExpr returns the value of the Resolvers field of the generated config passed to ce, either directly or
+// through the call creating the executable schema
+func resolversExpr(ce *ast.CallExpr) ast.Expr {
+	var res ast.Expr
+	for _, arg := range ce.Args {
+		ast.Inspect(arg, func(node ast.Node) bool {
+			if kv, ok := node.(*ast.KeyValueExpr); ok && res == nil && keyName(kv.Key) == "Resolvers" {
+				res = kv.Value
+			}
+			return res == nil
+		})
+	}
+	return res
+}
+
+// returnedType returns the type of the value returned by fn, as in *queryResolver for
+// func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }, or nil if the body of fn could not be found
+func returnedType(fn *types.Func, source PackageSource) types.Type {
+	files, info := source(fn.Pkg())
+	decl := findFuncDecl(fn, files, info)
+	if decl == nil || decl.Body == nil {
+		return nil
+	}
+	var res types.Type
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) == 1 && res == nil {
+				res = info.TypeOf(n.Results[0])
+			}
+		}
+		return res == nil
+	})
+	return res
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
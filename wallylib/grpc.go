@@ -0,0 +1,217 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"strings"
+)
+
+const grpcPkg = "google.golang.org/grpc"
+
+// isGRPCRegistration reports whether a function is a generated RegisterXxxServer function, which takes the server to
+// register the service on and the implementation of the service
+func isGRPCRegistration(name string, sig *types.Signature) bool {
+	if !strings.HasPrefix(name, "Register") || !strings.HasSuffix(name, "Server") || sig.Params().Len() != 2 {
+		return false
+	}
+	t := sig.Params().At(0).Type()
+	return isNamed(t, grpcPkg, "ServiceRegistrar") || isNamed(t, grpcPkg, "Server")
+}
+
+// resolveGRPCMethods returns the methods of the service registered by ce, which is either a call to a generated
+// RegisterXxxServer function or a direct call to RegisterService. The grpc.ServiceDesc of the service is read from
+// the generated code, found with source. Returns nil if the service descriptor could not be found
+func resolveGRPCMethods(fn *types.Func, ce *ast.CallExpr, pass *analysis.Pass, source PackageSource) []RPCMethod {
+	if len(ce.Args) != 2 {
+		return nil
+	}
+
+	descExpr, descInfo := ce.Args[0], pass.TypesInfo
+	if isGRPCRegistration(fn.Name(), fn.Type().(*types.Signature)) {
+		// Generated registration functions pass the descriptor to RegisterService
+		files, info := source(fn.Pkg())
+		register := findRegisterService(findFuncDecl(fn, files, info), info)
+		if register == nil {
+			return nil
+		}
+		descExpr, descInfo = register.Args[0], info
+	}
+
+	desc := serviceDescLit(descExpr, descInfo, source)
+	if desc == nil {
+		return nil
+	}
+	return desc.methods(pass.TypesInfo.TypeOf(ce.Args[1]))
+}
+
+type serviceDesc struct {
+	lit  *ast.CompositeLit
+	info *types.Info
+}
+
+// methods returns the methods and streams listed in the descriptor, resolved on impl
+func (d *serviceDesc) methods(impl types.Type) []RPCMethod {
+	var service string
+	var handlerType types.Type
+	var res []RPCMethod
+	for _, elt := range d.lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		switch keyName(kv.Key) {
+		case "ServiceName":
+			service = d.stringValue(kv.Value)
+		case "HandlerType":
+			// HandlerType is set to a nil pointer to the service interface, as in (*GreeterServer)(nil)
+			if ptr, ok := d.info.TypeOf(kv.Value).(*types.Pointer); ok {
+				handlerType = ptr.Elem()
+			}
+		case "Methods":
+			res = append(res, d.methodDescs(kv.Value, "MethodName", false)...)
+		case "Streams":
+			res = append(res, d.methodDescs(kv.Value, "StreamName", true)...)
+		}
+	}
+
+	for i := range res {
+		res[i].Service = service
+		res[i].FullMethod = "/" + service + "/" + res[i].Method
+		res[i].Impl = lookupImpl(impl, handlerType, res[i].Method)
+	}
+	return res
+}
+
+// methodDescs reads the names of a list of grpc.MethodDesc or grpc.StreamDesc literals
+func (d *serviceDesc) methodDescs(expr ast.Expr, nameKey string, streaming bool) []RPCMethod {
+	list, ok := ast.Unparen(expr).(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	var res []RPCMethod
+	for _, elt := range list.Elts {
+		if u, ok := elt.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			elt = u.X
+		}
+		lit, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		for _, field := range lit.Elts {
+			if kv, ok := field.(*ast.KeyValueExpr); ok && keyName(kv.Key) == nameKey {
+				if name := d.stringValue(kv.Value); name != "" {
+					res = append(res, RPCMethod{Method: name, Streaming: streaming})
+				}
+			}
+		}
+	}
+	return res
+}
+
+func (d *serviceDesc) stringValue(expr ast.Expr) string {
+	tv, ok := d.info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return ""
+	}
+	return constant.StringVal(tv.Value)
+}
+
+// serviceDescLit returns the grpc.ServiceDesc literal passed to RegisterService, either directly or through the
+// package level variable generated for it, as in &Greeter_ServiceDesc
+func serviceDescLit(expr ast.Expr, info *types.Info, source PackageSource) *serviceDesc {
+	expr = ast.Unparen(expr)
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = ast.Unparen(u.X)
+	}
+
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return &serviceDesc{lit: e, info: info}
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return nil
+	}
+
+	v, ok := info.Uses[ident].(*types.Var)
+	if !ok || v.Pkg() == nil {
+		return nil
+	}
+	files, varInfo := source(v.Pkg())
+	if lit, ok := ast.Unparen(findVarValue(v, files, varInfo)).(*ast.CompositeLit); ok {
+		return &serviceDesc{lit: lit, info: varInfo}
+	}
+	return nil
+}
+
+// findRegisterService returns the call to RegisterService made in the body of a registration function
+func findRegisterService(decl *ast.FuncDecl, info *types.Info) *ast.CallExpr {
+	if decl == nil || decl.Body == nil {
+		return nil
+	}
+	var res *ast.CallExpr
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		ce, ok := node.(*ast.CallExpr)
+		if !ok || res != nil {
+			return res == nil
+		}
+		if fn := funcObj(ce.Fun, info); fn != nil && fn.Name() == "RegisterService" && len(ce.Args) == 2 {
+			res = ce
+		}
+		return res == nil
+	})
+	return res
+}
+
+func findFuncDecl(fn *types.Func, files []*ast.File, info *types.Info) *ast.FuncDecl {
+	if info == nil {
+		return nil
+	}
+	for _, file := range files {
+		for _, d := range file.Decls {
+			if decl, ok := d.(*ast.FuncDecl); ok && info.Defs[decl.Name] == fn {
+				return decl
+			}
+		}
+	}
+	return nil
+}
+
+func findVarValue(v *types.Var, files []*ast.File, info *types.Info) ast.Expr {
+	if info == nil {
+		return nil
+	}
+	for _, file := range files {
+		for _, d := range file.Decls {
+			gen, ok := d.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for i, name := range vs.Names {
+					if info.Defs[name] == v && i < len(vs.Values) {
+						return vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func keyName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
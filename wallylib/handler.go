@@ -0,0 +1,111 @@
+package wallylib
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"path/filepath"
+)
+
+// Methods of the handler values passed to entry points other than HTTP routes, as in sarama.ConsumerGroupHandler,
+// lambda.Handler and cron.Job
+var entryHandlerMethods = []string{"ConsumeClaim", "Invoke", "Run"}
+
+// ResolveHandler returns a description of the handler passed to a route registration call. The handler is
+// the last argument that is either a function or a value implementing ServeHTTP. Values implementing one of
+// entryHandlerMethods are used otherwise, described by the method. Returns an empty string if no such argument exists
+func ResolveHandler(ce *ast.CallExpr, pass *analysis.Pass) string {
+	arg, method := HandlerArg(ce, pass)
+	switch {
+	case method != nil:
+		return method.FullName()
+	case arg != nil:
+		return DescribeHandlerExpr(arg, pass)
+	}
+	return ""
+}
+
+// HandlerArg returns the handler argument of a route registration call, as found by ResolveHandler, along with the
+// method run by the handler when it is a value implementing one of entryHandlerMethods
+func HandlerArg(ce *ast.CallExpr, pass *analysis.Pass) (ast.Expr, *types.Func) {
+	for i := len(ce.Args) - 1; i >= 0; i-- {
+		arg := ce.Args[i]
+		if !isHandlerType(pass.TypesInfo.TypeOf(arg)) {
+			continue
+		}
+		return arg, nil
+	}
+	for i := len(ce.Args) - 1; i >= 0; i-- {
+		t := pass.TypesInfo.TypeOf(ce.Args[i])
+		for _, name := range entryHandlerMethods {
+			if m := lookupImpl(t, nil, name); m != nil {
+				return ce.Args[i], m
+			}
+		}
+	}
+	return nil, nil
+}
+
+// DescribeHandlerExpr returns the fully qualified name of the function used in a handler expression
+// when possible, or a description of the expression otherwise
+func DescribeHandlerExpr(expr ast.Expr, pass *analysis.Pass) string {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return DescribeHandlerExpr(e.X, pass)
+	case *ast.Ident:
+		if fn, ok := pass.TypesInfo.ObjectOf(e).(*types.Func); ok {
+			return fn.FullName()
+		}
+	case *ast.SelectorExpr:
+		if sel, ok := pass.TypesInfo.Selections[e]; ok {
+			if fn, ok := sel.Obj().(*types.Func); ok {
+				return fn.FullName()
+			}
+		}
+		if fn, ok := pass.TypesInfo.ObjectOf(e.Sel).(*types.Func); ok {
+			return fn.FullName()
+		}
+	case *ast.FuncLit:
+		pos := pass.Fset.Position(e.Pos())
+		return fmt.Sprintf("func literal %s:%d", filepath.Base(pos.Filename), pos.Line)
+	case *ast.CompositeLit:
+		return types.TypeString(pass.TypesInfo.TypeOf(e), nil) + ".ServeHTTP"
+	case *ast.UnaryExpr:
+		if _, ok := e.X.(*ast.CompositeLit); ok && e.Op == token.AND {
+			return types.TypeString(pass.TypesInfo.TypeOf(e), nil) + ".ServeHTTP"
+		}
+	case *ast.CallExpr:
+		// Conversions such as http.HandlerFunc(h) wrap the actual handler
+		if tv, ok := pass.TypesInfo.Types[e.Fun]; ok && tv.IsType() && len(e.Args) == 1 {
+			return DescribeHandlerExpr(e.Args[0], pass)
+		}
+	}
+	return types.ExprString(expr)
+}
+
+func isHandlerType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if _, ok := t.Underlying().(*types.Signature); ok {
+		return true
+	}
+	return hasMethod(t, "ServeHTTP")
+}
+
+func hasMethod(t types.Type, name string) bool {
+	for _, typ := range []types.Type{t, types.NewPointer(t)} {
+		if _, isPtr := t.(*types.Pointer); isPtr && typ != t {
+			continue
+		}
+		mset := types.NewMethodSet(typ)
+		for i := 0; i < mset.Len(); i++ {
+			if mset.At(i).Obj().Name() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+)
+
+// maxHandlerDepth limits how many calls are followed from a handler
+const maxHandlerDepth = 6
+
+// FuncSource returns the declaration of a function along with the pass of its package, or a nil declaration for
+// functions outside the analyzed packages
+type FuncSource func(fn *types.Func) (*ast.FuncDecl, *analysis.Pass)
+
+// handlerVisitor is called for every node of a handler and of the functions it calls. It returns true when it
+// handled a call, in which case the called function is not followed
+type handlerVisitor func(node ast.Node, scope *walkScope) bool
+
+type handlerWalker struct {
+	source  FuncSource
+	visit   handlerVisitor
+	visited map[*ast.CallExpr]bool
+	stack   map[*types.Func]bool
+}
+
+// walkScope is a function being walked, along with the arguments it was called with
+type walkScope struct {
+	pass   *analysis.Pass
+	params map[types.Object]ast.Expr
+	caller *walkScope
+}
+
+// resolve returns the argument passed for expr if expr is a parameter of the function, following parameters up the
+// calls that led to it, along with the scope of the argument. Other expressions are returned as they are
+func (s *walkScope) resolve(expr ast.Expr) (ast.Expr, *walkScope) {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok || s.caller == nil {
+		return expr, s
+	}
+	arg, ok := s.params[s.pass.TypesInfo.ObjectOf(id)]
+	if !ok {
+		return expr, s
+	}
+	return s.caller.resolve(arg)
+}
+
+// typeOf returns the type of expr, or of the argument passed for it when it is a parameter
+func (s *walkScope) typeOf(expr ast.Expr) types.Type {
+	expr, scope := s.resolve(expr)
+	return scope.pass.TypesInfo.TypeOf(expr)
+}
+
+// walkHandler finds the handler passed to the route registration ce, which is the last argument that is either a
+// function or a value implementing ServeHTTP, and calls visit for every node of its body and of the functions it
+// calls that source can find. It reports whether the handler was found
+func walkHandler(ce *ast.CallExpr, pass *analysis.Pass, source FuncSource, visit handlerVisitor) bool {
+	w := &handlerWalker{
+		source:  source,
+		visit:   visit,
+		visited: make(map[*ast.CallExpr]bool),
+		stack:   make(map[*types.Func]bool),
+	}
+	for i := len(ce.Args) - 1; i >= 0; i-- {
+		if isHandlerType(pass.TypesInfo.TypeOf(ce.Args[i])) {
+			return w.handlerExpr(ce.Args[i], pass, 0)
+		}
+	}
+	return false
+}
+
+// handlerExpr finds the function body of a handler expression and walks it. It reports whether a body was found
+func (w *handlerWalker) handlerExpr(expr ast.Expr, pass *analysis.Pass, depth int) bool {
+	if depth > maxHandlerDepth {
+		return false
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		w.walk(e.Body, &walkScope{pass: pass}, depth)
+		return true
+	case *ast.Ident, *ast.SelectorExpr:
+		if fn := funcObj(e, pass.TypesInfo); fn != nil {
+			return w.function(fn, nil, nil, depth)
+		}
+		// Handler values, as in h := &handler{}, implement ServeHTTP
+		return w.serveHTTP(pass.TypesInfo.TypeOf(e), depth)
+	case *ast.CompositeLit, *ast.UnaryExpr:
+		return w.serveHTTP(pass.TypesInfo.TypeOf(e), depth)
+	case *ast.CallExpr:
+		// Conversions such as http.HandlerFunc(h) and middleware such as mw(h) take the actual handler as an argument
+		for i := len(e.Args) - 1; i >= 0; i-- {
+			if isHandlerType(pass.TypesInfo.TypeOf(e.Args[i])) {
+				return w.handlerExpr(e.Args[i], pass, depth+1)
+			}
+		}
+		// Otherwise the call builds the handler, as in newHandler(db), and we look at what it returns
+		fn := funcObj(e.Fun, pass.TypesInfo)
+		if fn == nil {
+			return false
+		}
+		decl, declPass := w.source(fn.Origin())
+		if decl == nil || decl.Body == nil {
+			return false
+		}
+		found := false
+		ast.Inspect(decl.Body, func(node ast.Node) bool {
+			// Return statements of function literals declared in the builder do not return the handler
+			if _, ok := node.(*ast.FuncLit); ok {
+				return false
+			}
+			if ret, ok := node.(*ast.ReturnStmt); ok {
+				for _, res := range ret.Results {
+					if isHandlerType(declPass.TypesInfo.TypeOf(res)) && w.handlerExpr(res, declPass, depth+1) {
+						found = true
+					}
+				}
+			}
+			return true
+		})
+		return found
+	}
+	return false
+}
+
+// serveHTTP walks the ServeHTTP method of t
+func (w *handlerWalker) serveHTTP(t types.Type, depth int) bool {
+	if t == nil {
+		return false
+	}
+	if _, isPtr := t.(*types.Pointer); !isPtr {
+		t = types.NewPointer(t)
+	}
+	sel := types.NewMethodSet(t).Lookup(nil, "ServeHTTP")
+	if sel == nil {
+		return false
+	}
+	fn, ok := sel.Obj().(*types.Func)
+	return ok && w.function(fn, nil, nil, depth)
+}
+
+// function walks fn, if it is declared in one of the analyzed packages. When fn is called by ce from the function of
+// caller, its parameters are bound to the arguments of the call
+func (w *handlerWalker) function(fn *types.Func, ce *ast.CallExpr, caller *walkScope, depth int) bool {
+	fn = fn.Origin()
+	if w.stack[fn] {
+		return true
+	}
+	if ce != nil {
+		if w.visited[ce] {
+			return true
+		}
+		w.visited[ce] = true
+	}
+	decl, pass := w.source(fn)
+	if decl == nil || decl.Body == nil {
+		return false
+	}
+
+	scope := &walkScope{pass: pass, caller: caller, params: make(map[types.Object]ast.Expr)}
+	if ce != nil && !ce.Ellipsis.IsValid() {
+		i := 0
+		for _, field := range decl.Type.Params.List {
+			for _, name := range field.Names {
+				// Variadic params receive several arguments, so they are left unbound
+				if _, variadic := field.Type.(*ast.Ellipsis); !variadic && i < len(ce.Args) {
+					scope.params[pass.TypesInfo.Defs[name]] = ce.Args[i]
+				}
+				i++
+			}
+			if len(field.Names) == 0 {
+				i++
+			}
+		}
+	}
+
+	w.stack[fn] = true
+	w.walk(decl.Body, scope, depth)
+	delete(w.stack, fn)
+	return true
+}
+
+// walk calls visit for every node of body, following calls to functions of the analyzed packages that the visitor
+// did not handle
+func (w *handlerWalker) walk(body ast.Node, scope *walkScope, depth int) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		if node == nil || w.visit(node, scope) {
+			return true
+		}
+		if ce, ok := node.(*ast.CallExpr); ok && depth < maxHandlerDepth {
+			if fn := funcObj(ce.Fun, scope.pass.TypesInfo); fn != nil {
+				w.function(fn, ce, scope, depth+1)
+			}
+		}
+		return true
+	})
+}
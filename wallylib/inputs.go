@@ -0,0 +1,374 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of request inputs
+const (
+	QueryInput  = "query"
+	FormInput   = "form"
+	FileInput   = "file"
+	PathInput   = "path"
+	HeaderInput = "header"
+	CookieInput = "cookie"
+	BodyInput   = "body"
+)
+
+// Input is a part of a request read by a handler
+type Input struct {
+	Kind string
+	// Name is the name of the query param, form field, header, etc. It is empty for request bodies
+	Name string `json:",omitempty"`
+	// Type is the Go type the input is read as, which for request bodies is the type the body is decoded into
+	Type string
+}
+
+type inputAccessor struct {
+	kind string
+	// arg is the position of the argument holding the name of the input, or the value the input is decoded into
+	// for body inputs. It is -1 for accessors whose result is indexed by name, as in mux.Vars(r)["id"]
+	arg int
+	typ string
+}
+
+// maxBodyDepth limits how many variables and readers are followed back to r.Body
+const maxBodyDepth = 8
+
+// Accessors by function full name. Functions from packages with a major version suffix are also matched without it
+var inputAccessors = map[string]inputAccessor{
+	"(*net/http.Request).FormValue":     {FormInput, 0, "string"},
+	"(*net/http.Request).PostFormValue": {FormInput, 0, "string"},
+	"(*net/http.Request).FormFile":      {FileInput, 0, "mime/multipart.File"},
+	"(*net/http.Request).PathValue":     {PathInput, 0, "string"},
+	"(*net/http.Request).Cookie":        {CookieInput, 0, "*net/http.Cookie"},
+	"(net/http.Header).Get":             {HeaderInput, 0, "string"},
+	"(net/http.Header).Values":          {HeaderInput, 0, "[]string"},
+	"(net/url.Values).Get":              {QueryInput, 0, "string"},
+	"(net/url.Values).Has":              {QueryInput, 0, "bool"},
+
+	"github.com/go-chi/chi.URLParam": {PathInput, 1, "string"},
+	"github.com/gorilla/mux.Vars":    {PathInput, -1, "string"},
+
+	"(*github.com/gin-gonic/gin.Context).Param":           {PathInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).Query":           {QueryInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).DefaultQuery":    {QueryInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).GetQuery":        {QueryInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).QueryArray":      {QueryInput, 0, "[]string"},
+	"(*github.com/gin-gonic/gin.Context).PostForm":        {FormInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).DefaultPostForm": {FormInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).GetPostForm":     {FormInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).FormFile":        {FileInput, 0, "*mime/multipart.FileHeader"},
+	"(*github.com/gin-gonic/gin.Context).GetHeader":       {HeaderInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).Cookie":          {CookieInput, 0, "string"},
+	"(*github.com/gin-gonic/gin.Context).BindJSON":        {BodyInput, 0, ""},
+	"(*github.com/gin-gonic/gin.Context).ShouldBindJSON":  {BodyInput, 0, ""},
+	"(*github.com/gin-gonic/gin.Context).Bind":            {BodyInput, 0, ""},
+	"(*github.com/gin-gonic/gin.Context).ShouldBind":      {BodyInput, 0, ""},
+
+	"(github.com/labstack/echo.Context).Param":      {PathInput, 0, "string"},
+	"(github.com/labstack/echo.Context).QueryParam": {QueryInput, 0, "string"},
+	"(github.com/labstack/echo.Context).FormValue":  {FormInput, 0, "string"},
+	"(github.com/labstack/echo.Context).FormFile":   {FileInput, 0, "*mime/multipart.FileHeader"},
+	"(github.com/labstack/echo.Context).Bind":       {BodyInput, 0, ""},
+
+	"(*encoding/json.Decoder).Decode": {BodyInput, 0, ""},
+	"(*encoding/xml.Decoder).Decode":  {BodyInput, 0, ""},
+	"encoding/json.Unmarshal":         {BodyInput, 1, ""},
+	"encoding/xml.Unmarshal":          {BodyInput, 1, ""},
+	"io.ReadAll":                      {BodyInput, -1, "[]byte"},
+	"io/ioutil.ReadAll":               {BodyInput, -1, "[]byte"},
+}
+
+// dataDecoders decode any data, and only read the request body when their data comes from it, as in
+// json.NewDecoder(r.Body).Decode(&v)
+var dataDecoders = map[string]bool{
+	"(*encoding/json.Decoder).Decode": true,
+	"(*encoding/xml.Decoder).Decode":  true,
+	"encoding/json.Unmarshal":         true,
+	"encoding/xml.Unmarshal":          true,
+}
+
+// bodyReaders are functions returning readers, decoders or bytes of the reader passed as the argument at the given
+// position, which read the request body when passed r.Body
+var bodyReaders = map[string]int{
+	"encoding/json.NewDecoder": 0,
+	"encoding/xml.NewDecoder":  0,
+	"io.ReadAll":               0,
+	"io/ioutil.ReadAll":        0,
+	"io.LimitReader":           0,
+	"bytes.NewReader":          0,
+	"bytes.NewBuffer":          0,
+	"net/http.MaxBytesReader":  1,
+}
+
+type inputResolver struct {
+	inputs []Input
+	seen   map[Input]bool
+}
+
+// ResolveInputs returns the request inputs read by the handler passed to the route registration ce: query params,
+// form fields, path params, headers, cookies and the types request bodies are decoded into. Calls made by the handler
+// to functions found by source are followed, so inputs read by helpers within the analyzed packages are included.
+// Returns nil if the handler could not be found, and an empty slice if it reads no inputs
+func ResolveInputs(ce *ast.CallExpr, pass *analysis.Pass, source FuncSource) []Input {
+	r := &inputResolver{
+		inputs: []Input{},
+		seen:   make(map[Input]bool),
+	}
+	if !walkHandler(ce, pass, source, r.visit) {
+		return nil
+	}
+
+	sort.SliceStable(r.inputs, func(i, j int) bool {
+		if r.inputs[i].Kind != r.inputs[j].Kind {
+			return r.inputs[i].Kind < r.inputs[j].Kind
+		}
+		return r.inputs[i].Name < r.inputs[j].Name
+	})
+	return r.inputs
+}
+
+// visit collects inputs read by index expressions and accessor calls. Calls to accessors are not followed
+func (r *inputResolver) visit(node ast.Node, scope *walkScope) bool {
+	switch e := node.(type) {
+	case *ast.IndexExpr:
+		r.indexInput(e, scope)
+	case *ast.CallExpr:
+		fn := funcObj(e.Fun, scope.pass.TypesInfo)
+		if fn == nil {
+			return false
+		}
+		if acc, ok := lookupAccessor(fn); ok {
+			if !dataDecoders[fn.FullName()] || decodesRequestBody(e, scope) {
+				r.callInput(e, acc, scope)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// callInput adds the input read by a call to an accessor
+func (r *inputResolver) callInput(ce *ast.CallExpr, acc inputAccessor, scope *walkScope) {
+	kind, typ := acc.kind, acc.typ
+	if sel, ok := ast.Unparen(ce.Fun).(*ast.SelectorExpr); ok {
+		if kind == QueryInput && isFormField(sel.X) {
+			kind = FormInput
+		}
+		// Calls on w.Header() read the headers of the response
+		if _, isCall := ast.Unparen(sel.X).(*ast.CallExpr); kind == HeaderInput && isCall {
+			return
+		}
+	}
+
+	if kind == BodyInput {
+		if acc.arg < 0 {
+			// io.ReadAll only reads the body when passed r.Body
+			if len(ce.Args) == 0 || !isRequestBody(ce.Args[0], scope.pass) {
+				return
+			}
+		} else {
+			if acc.arg >= len(ce.Args) {
+				return
+			}
+			typ = decodedType(scope.typeOf(ce.Args[acc.arg]))
+		}
+		r.add(Input{Kind: kind, Type: typ})
+		return
+	}
+
+	if acc.arg < 0 || acc.arg >= len(ce.Args) {
+		return
+	}
+	r.add(Input{Kind: kind, Name: inputName(ce.Args[acc.arg], scope), Type: typ})
+}
+
+// indexInput adds inputs read by indexing, as in mux.Vars(r)["id"], r.URL.Query()["id"] or r.Header["X-Id"]
+func (r *inputResolver) indexInput(e *ast.IndexExpr, scope *walkScope) {
+	x := ast.Unparen(e.X)
+	if call, ok := x.(*ast.CallExpr); ok {
+		if fn := funcObj(call.Fun, scope.pass.TypesInfo); fn != nil {
+			if acc, ok := lookupAccessor(fn); ok && acc.arg < 0 && acc.kind == PathInput {
+				r.add(Input{Kind: acc.kind, Name: inputName(e.Index, scope), Type: acc.typ})
+			}
+		}
+	}
+
+	named, ok := scope.pass.TypesInfo.TypeOf(x).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return
+	}
+	switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+	case "net/url.Values":
+		kind := QueryInput
+		if isFormField(x) {
+			kind = FormInput
+		}
+		r.add(Input{Kind: kind, Name: inputName(e.Index, scope), Type: "[]string"})
+	case "net/http.Header":
+		if sel, ok := x.(*ast.SelectorExpr); ok && sel.Sel.Name == "Header" {
+			r.add(Input{Kind: HeaderInput, Name: inputName(e.Index, scope), Type: "[]string"})
+		}
+	}
+}
+
+func (r *inputResolver) add(in Input) {
+	if r.seen[in] {
+		return
+	}
+	r.seen[in] = true
+	r.inputs = append(r.inputs, in)
+}
+
+func lookupAccessor(fn *types.Func) (inputAccessor, bool) {
+	name := fn.FullName()
+	if acc, ok := inputAccessors[name]; ok {
+		return acc, true
+	}
+	// Strip major version suffixes, as in github.com/go-chi/chi/v5
+	if fn.Pkg() != nil {
+		if unversioned := unversionedPath(fn.Pkg().Path()); unversioned != fn.Pkg().Path() {
+			acc, ok := inputAccessors[strings.Replace(name, fn.Pkg().Path(), unversioned, 1)]
+			return acc, ok
+		}
+	}
+	return inputAccessor{}, false
+}
+
+// isFormField reports whether x is the Form or PostForm field of a request, as opposed to the values returned by
+// URL.Query()
+func isFormField(x ast.Expr) bool {
+	sel, ok := ast.Unparen(x).(*ast.SelectorExpr)
+	return ok && (sel.Sel.Name == "Form" || sel.Sel.Name == "PostForm")
+}
+
+func isRequestBody(expr ast.Expr, pass *analysis.Pass) bool {
+	sel, ok := ast.Unparen(expr).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Body" {
+		return false
+	}
+	return isRequestType(pass.TypesInfo.TypeOf(sel.X))
+}
+
+// decodesRequestBody reports whether a decoding call reads the request body: the decoder a Decode method is called
+// on, or the data passed to Unmarshal, comes from r.Body
+func decodesRequestBody(ce *ast.CallExpr, scope *walkScope) bool {
+	if sel, ok := ast.Unparen(ce.Fun).(*ast.SelectorExpr); ok {
+		if selection, ok := scope.pass.TypesInfo.Selections[sel]; ok && selection.Kind() == types.MethodVal {
+			return readsRequestBody(sel.X, scope, 0)
+		}
+	}
+	return len(ce.Args) > 0 && readsRequestBody(ce.Args[0], scope, 0)
+}
+
+// readsRequestBody reports whether expr is r.Body or is built from it by a body reader, as in json.NewDecoder(r.Body)
+// or io.ReadAll(r.Body). Variables are followed to the values assigned to them, and parameters to their arguments
+func readsRequestBody(expr ast.Expr, scope *walkScope, depth int) bool {
+	if depth > maxBodyDepth {
+		return false
+	}
+	expr, scope = scope.resolve(expr)
+	if isRequestBody(expr, scope.pass) {
+		return true
+	}
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		fn := funcObj(e.Fun, scope.pass.TypesInfo)
+		if fn == nil {
+			return false
+		}
+		if arg, ok := bodyReaders[fn.FullName()]; ok && arg < len(e.Args) {
+			return readsRequestBody(e.Args[arg], scope, depth+1)
+		}
+	case *ast.UnaryExpr:
+		return readsRequestBody(e.X, scope, depth+1)
+	case *ast.Ident:
+		for _, value := range assignedValues(e, scope.pass) {
+			if readsRequestBody(value, scope, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// assignedValues returns the values assigned to the local variable id in the file declaring it, including the calls
+// of multi-value assignments, as in body, err := io.ReadAll(r.Body)
+func assignedValues(id *ast.Ident, pass *analysis.Pass) []ast.Expr {
+	obj, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope() {
+		return nil
+	}
+	var values []ast.Expr
+	assigned := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, l := range lhs {
+			lid, ok := l.(*ast.Ident)
+			if !ok || pass.TypesInfo.ObjectOf(lid) != obj {
+				continue
+			}
+			if len(lhs) == len(rhs) {
+				values = append(values, rhs[i])
+			} else if len(rhs) == 1 {
+				values = append(values, rhs[0])
+			}
+		}
+	}
+	for _, file := range pass.Files {
+		if obj.Pos() < file.FileStart || obj.Pos() > file.FileEnd {
+			continue
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch s := node.(type) {
+			case *ast.AssignStmt:
+				assigned(s.Lhs, s.Rhs)
+			case *ast.ValueSpec:
+				lhs := make([]ast.Expr, len(s.Names))
+				for i, name := range s.Names {
+					lhs[i] = name
+				}
+				assigned(lhs, s.Values)
+			}
+			return true
+		})
+	}
+	return values
+}
+
+func isRequestType(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "net/http" && named.Obj().Name() == "Request"
+}
+
+// decodedType returns the type a body is decoded into given the type of the argument, which is usually a pointer
+func decodedType(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	return types.TypeString(t, nil)
+}
+
+// inputName resolves the name of an input with GetValueFromExp, without the quotes of string literals. Parameters are
+// resolved to the arguments passed for them, as in getParam(r, "id")
+func inputName(expr ast.Expr, scope *walkScope) string {
+	expr, scope = scope.resolve(expr)
+	val := strings.TrimSpace(GetValueFromExp(expr, scope.pass))
+	if unquoted, err := strconv.Unquote(val); err == nil {
+		return unquoted
+	}
+	if val == "" {
+		return "<could not resolve>"
+	}
+	return val
+}
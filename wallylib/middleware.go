@@ -0,0 +1,379 @@
+package wallylib
+
+import (
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/ssa"
+	"sort"
+	"strings"
+)
+
+// maxChainDepth limits how many wrapper calls and derived routers are followed when resolving a middleware chain
+const maxChainDepth = 16
+
+// Scopes of a middleware in a chain
+const (
+	RouterMiddleware  = "router"
+	HandlerMiddleware = "handler"
+)
+
+// Middleware is a function found wrapping the handler of a route
+type Middleware struct {
+	Name string
+	// Scope is RouterMiddleware for middleware applied to the router the route is registered on, and
+	// HandlerMiddleware for middleware wrapping or passed along with the handler argument
+	Scope string
+	// Recovers is set when the middleware recovers from panics of the handlers it wraps
+	Recovers bool
+	Func     *ssa.Function `json:"-"`
+}
+
+type chainResolver struct {
+	site     ssa.CallInstruction
+	chain    []Middleware
+	handlers []*ssa.Function
+	seen     map[string]bool
+	visited  map[ssa.Value]bool
+}
+
+// ResolveMiddlewareChain reconstructs from SSA the ordered chain of middleware applied to the route registered by
+// site, from the outermost to the innermost. Router middleware comes first: middleware of the routers a router was
+// derived from with Group, With or Route, then Use calls on the router made before the registration. Handler
+// middleware follows, taken from calls wrapping handler arguments (mw(h)), builders (alice.New(mw).Then(h)) and
+// handler lists where all but the last element are middleware (r.GET("/", mw, h)). It also returns the handler
+// functions found at the end of the chain
+func ResolveMiddlewareChain(site ssa.CallInstruction) ([]Middleware, []*ssa.Function) {
+	r := &chainResolver{
+		site:    site,
+		chain:   []Middleware{},
+		seen:    make(map[string]bool),
+		visited: make(map[ssa.Value]bool),
+	}
+
+	recv, args := splitReceiver(site.Common())
+	if recv != nil {
+		r.routerChain(recv, 0)
+	}
+	for _, arg := range args {
+		if isHandlerValue(arg) {
+			r.handlerChain(arg, HandlerMiddleware, 0)
+		}
+	}
+	return r.chain, r.handlers
+}
+
+// routerChain collects middleware applied to the router recv
+func (r *chainResolver) routerChain(recv ssa.Value, depth int) {
+	if depth > maxChainDepth {
+		return
+	}
+
+	recv = unwrapValue(recv)
+	if call, ok := recv.(*ssa.Call); ok && routerDeriveMethods[methodName(call.Common())] {
+		parent, args := splitReceiver(call.Common())
+		if parent != nil {
+			r.routerChain(parent, depth+1)
+		}
+		for _, arg := range args {
+			r.middlewareValue(arg, RouterMiddleware, depth+1)
+		}
+	}
+
+	for _, use := range r.useCalls(recv) {
+		_, args := splitReceiver(use.Common())
+		for _, arg := range args {
+			r.middlewareValue(arg, RouterMiddleware, depth+1)
+		}
+	}
+}
+
+// handlerChain collects the middleware wrapping the handler value v and records the handler it ends at
+func (r *chainResolver) handlerChain(v ssa.Value, scope string, depth int) {
+	if depth > maxChainDepth || v == nil || r.visited[v] {
+		return
+	}
+	r.visited[v] = true
+
+	v = unwrapValue(v)
+	if elems, ok := sliceElements(v); ok {
+		// In lists of handlers such as r.GET("/", mw1, mw2, h), every element but the last is middleware
+		for i, elem := range elems {
+			if i == len(elems)-1 {
+				r.handlerChain(elem, scope, depth+1)
+			} else {
+				r.middlewareValue(elem, scope, depth+1)
+			}
+		}
+		return
+	}
+
+	switch val := v.(type) {
+	case *ssa.Function:
+		r.handlers = append(r.handlers, boundTarget(val))
+	case *ssa.MakeClosure:
+		if fn, ok := val.Fn.(*ssa.Function); ok {
+			r.handlers = append(r.handlers, boundTarget(fn))
+		}
+	case *ssa.Phi:
+		for _, edge := range val.Edges {
+			r.handlerChain(edge, scope, depth+1)
+		}
+	case *ssa.Call:
+		common := val.Common()
+		recv, args := splitReceiver(common)
+		var inner []ssa.Value
+		for _, arg := range args {
+			if isHandlerValue(arg) {
+				inner = append(inner, arg)
+			}
+		}
+		// Calls that do not take a handler build one, as in newHandler(db), and are not middleware
+		if len(inner) == 0 {
+			return
+		}
+		if builder, ok := unwrapValue(recv).(*ssa.Call); ok {
+			// Builders such as alice.New(mw).Then(h) carry the middleware in the calls that create them
+			r.builderChain(builder, scope, depth+1)
+		} else if factory, ok := common.Value.(*ssa.Call); ok && !common.IsInvoke() {
+			// Middleware returned by a factory and called right away, as in middleware.Logger()(h)
+			r.add(calleeFunc(factory.Common()), calleeName(factory.Common()), scope)
+		} else {
+			r.add(calleeFunc(common), calleeName(common), scope)
+		}
+		for _, arg := range inner {
+			r.handlerChain(arg, scope, depth+1)
+		}
+	}
+}
+
+// builderChain collects middleware passed to the calls that created a middleware builder
+func (r *chainResolver) builderChain(call *ssa.Call, scope string, depth int) {
+	if depth > maxChainDepth {
+		return
+	}
+	recv, args := splitReceiver(call.Common())
+	if parent, ok := unwrapValue(recv).(*ssa.Call); ok {
+		r.builderChain(parent, scope, depth+1)
+	}
+	for _, arg := range args {
+		r.middlewareValue(arg, scope, depth+1)
+	}
+}
+
+// middlewareValue adds middleware passed as a value, as in r.Use(mw), r.Use(mw()) or r.Use(v.Verify)
+func (r *chainResolver) middlewareValue(v ssa.Value, scope string, depth int) {
+	if depth > maxChainDepth || v == nil {
+		return
+	}
+
+	v = unwrapValue(v)
+	if elems, ok := sliceElements(v); ok {
+		for _, elem := range elems {
+			r.middlewareValue(elem, scope, depth+1)
+		}
+		return
+	}
+
+	switch val := v.(type) {
+	case *ssa.Function:
+		fn := boundTarget(val)
+		r.add(fn, fn.String(), scope)
+	case *ssa.MakeClosure:
+		if fn, ok := val.Fn.(*ssa.Function); ok {
+			fn = boundTarget(fn)
+			r.add(fn, fn.String(), scope)
+		}
+	case *ssa.Call:
+		// Middleware factories, as in r.Use(middleware.Logger()) or r.GET("/", auth.Required(), h)
+		r.add(calleeFunc(val.Common()), calleeName(val.Common()), scope)
+	}
+}
+
+func (r *chainResolver) add(fn *ssa.Function, name, scope string) {
+	if fn != nil {
+		name = fn.String()
+	}
+	if name == "" || r.seen[name] {
+		return
+	}
+	r.seen[name] = true
+	r.chain = append(r.chain, Middleware{Name: name, Scope: scope, Func: fn})
+}
+
+// useCalls returns the Use calls on recv made in the function of the registration before it, in source order
+func (r *chainResolver) useCalls(recv ssa.Value) []ssa.CallInstruction {
+	fn := r.site.Parent()
+	if fn == nil {
+		return nil
+	}
+
+	var calls []ssa.CallInstruction
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			call, ok := instr.(ssa.CallInstruction)
+			if !ok || call.Pos() >= r.site.Pos() || methodName(call.Common()) != "Use" {
+				continue
+			}
+			if useRecv, _ := splitReceiver(call.Common()); useRecv != nil && sameValue(unwrapValue(useRecv), recv) {
+				calls = append(calls, call)
+			}
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Pos() < calls[j].Pos() })
+	return calls
+}
+
+// splitReceiver returns the receiver of a method call, if any, and the remaining arguments
+func splitReceiver(common *ssa.CallCommon) (ssa.Value, []ssa.Value) {
+	if common.IsInvoke() {
+		return common.Value, common.Args
+	}
+	if fn := common.StaticCallee(); fn != nil && fn.Signature.Recv() != nil && len(common.Args) > 0 {
+		return common.Args[0], common.Args[1:]
+	}
+	return nil, common.Args
+}
+
+func calleeFunc(common *ssa.CallCommon) *ssa.Function {
+	if common.IsInvoke() {
+		return nil
+	}
+	if fn := common.StaticCallee(); fn != nil {
+		return boundTarget(fn)
+	}
+	return nil
+}
+
+// calleeName returns the full name of the function called, which for interface method calls is the name of the
+// interface method
+func calleeName(common *ssa.CallCommon) string {
+	if common.IsInvoke() {
+		return common.Method.FullName()
+	}
+	if fn := calleeFunc(common); fn != nil {
+		return fn.String()
+	}
+	return ""
+}
+
+// methodName returns the name of the method called, or an empty string for calls to functions
+func methodName(common *ssa.CallCommon) string {
+	if common.IsInvoke() {
+		return common.Method.Name()
+	}
+	if fn := common.StaticCallee(); fn != nil && fn.Signature.Recv() != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// boundTarget returns the method called by a bound method wrapper, as created for method values such as v.Verify,
+// or fn itself
+func boundTarget(fn *ssa.Function) *ssa.Function {
+	if !strings.HasSuffix(fn.Name(), "$bound") {
+		return fn
+	}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			if call, ok := instr.(ssa.CallInstruction); ok {
+				if callee := call.Common().StaticCallee(); callee != nil {
+					return callee
+				}
+			}
+		}
+	}
+	return fn
+}
+
+// unwrapValue strips conversions and interface boxing, as in http.HandlerFunc(h) or passing h as an http.Handler
+func unwrapValue(v ssa.Value) ssa.Value {
+	for {
+		switch val := v.(type) {
+		case *ssa.MakeInterface:
+			v = val.X
+		case *ssa.ChangeType:
+			v = val.X
+		case *ssa.Convert:
+			v = val.X
+		case *ssa.ChangeInterface:
+			v = val.X
+		default:
+			return v
+		}
+	}
+}
+
+// sliceElements returns the values stored in a slice literal, such as the one built for variadic arguments, by index
+func sliceElements(v ssa.Value) ([]ssa.Value, bool) {
+	slice, ok := v.(*ssa.Slice)
+	if !ok {
+		return nil, false
+	}
+	alloc, ok := slice.X.(*ssa.Alloc)
+	if !ok {
+		return nil, false
+	}
+
+	type elem struct {
+		idx int64
+		pos token.Pos
+		val ssa.Value
+	}
+	var elems []elem
+	for _, ref := range *alloc.Referrers() {
+		ia, ok := ref.(*ssa.IndexAddr)
+		if !ok {
+			continue
+		}
+		idx, ok := ia.Index.(*ssa.Const)
+		if !ok {
+			continue
+		}
+		for _, iaRef := range *ia.Referrers() {
+			if store, ok := iaRef.(*ssa.Store); ok && store.Addr == ia {
+				elems = append(elems, elem{idx: idx.Int64(), pos: store.Pos(), val: store.Val})
+			}
+		}
+	}
+	sort.SliceStable(elems, func(i, j int) bool { return elems[i].idx < elems[j].idx })
+
+	res := make([]ssa.Value, 0, len(elems))
+	for _, e := range elems {
+		res = append(res, e.val)
+	}
+	return res, true
+}
+
+// sameValue reports whether a and b are the same router, either as the same SSA value or loaded from the same
+// global or struct field, as in s.router.Use(mw) followed by s.router.Handle(...)
+func sameValue(a, b ssa.Value) bool {
+	if a == b {
+		return true
+	}
+	la, aOk := a.(*ssa.UnOp)
+	lb, bOk := b.(*ssa.UnOp)
+	if !aOk || !bOk || la.Op != token.MUL || lb.Op != token.MUL {
+		return false
+	}
+	return sameAddr(la.X, lb.X)
+}
+
+func sameAddr(a, b ssa.Value) bool {
+	if a == b {
+		return true
+	}
+	fa, aOk := a.(*ssa.FieldAddr)
+	fb, bOk := b.(*ssa.FieldAddr)
+	if aOk && bOk {
+		return fa.Field == fb.Field && sameValue(fa.X, fb.X)
+	}
+	return false
+}
+
+func isHandlerValue(v ssa.Value) bool {
+	t := v.Type()
+	if s, ok := t.Underlying().(*types.Slice); ok {
+		t = s.Elem()
+	}
+	return isHandlerType(t)
+}
@@ -6,8 +6,14 @@ import (
 	"github.com/hex0punk/wally/checker"
 	"github.com/hex0punk/wally/indicator"
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func ResolveParams(params []indicator.RouteParam, sig *types.Signature, ce *ast.CallExpr, pass *analysis.Pass) map[string]string {
@@ -15,11 +21,17 @@ func ResolveParams(params []indicator.RouteParam, sig *types.Signature, ce *ast.
 	for _, param := range params {
 		param := param
 		val := ""
-		if param.Name != "" && sig != nil {
+		if param.Field != "" {
+			val = ResolveParamField(param, sig, ce, pass)
+		} else if param.Name != "" && sig != nil {
 			val = ResolveParamFromName(param.Name, sig, ce, pass)
 		} else {
 			val = ResolveParamFromPos(param.Pos, ce, pass)
 		}
+		if param.Field != "" {
+			resolvedParams[param.Field] = val
+			continue
+		}
 		resolvedParams[param.Name] = val
 	}
 	return resolvedParams
@@ -33,6 +45,69 @@ func ResolveParamFromPos(pos int, param *ast.CallExpr, pass *analysis.Pass) stri
 	return ""
 }
 
+// ResolveParamField resolves the value of the field param.Field in the struct literal passed as param. Pointer
+// helpers wrapping the value, such as aws.String("..."), are looked through
+func ResolveParamField(param indicator.RouteParam, sig *types.Signature, ce *ast.CallExpr, pass *analysis.Pass) string {
+	pos := param.Pos
+	if param.Name != "" && sig != nil {
+		var err error
+		if pos, err = GetParamPos(sig, param.Name); err != nil {
+			return ""
+		}
+	}
+	if pos >= len(ce.Args) {
+		return ""
+	}
+
+	arg := ast.Unparen(ce.Args[pos])
+	if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		arg = ast.Unparen(u.X)
+	}
+	lit, ok := arg.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	return resolveField(lit, param.Field, pass)
+}
+
+// ResolveLiteralParams resolves params naming fields of a struct literal matched by an indicator, as in the Use of
+// cobra.Command{Use: "serve"}. Params are keyed by field
+func ResolveLiteralParams(params []indicator.RouteParam, lit *ast.CompositeLit, pass *analysis.Pass) map[string]string {
+	resolvedParams := make(map[string]string)
+	for _, param := range params {
+		if param.Field != "" {
+			resolvedParams[param.Field] = resolveField(lit, param.Field, pass)
+		}
+	}
+	return resolvedParams
+}
+
+// resolveField resolves the value of field in lit. Pointer helpers wrapping the value, such as aws.String("..."),
+// are looked through
+func resolveField(lit *ast.CompositeLit, field string, pass *analysis.Pass) string {
+	value := LiteralField(lit, field)
+	if value == nil {
+		return ""
+	}
+	if call, ok := value.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		// A call returning a pointer to its only argument, as in aws.String
+		if ptr, ok := pass.TypesInfo.TypeOf(call).(*types.Pointer); ok && types.Identical(ptr.Elem(), pass.TypesInfo.TypeOf(call.Args[0])) {
+			value = ast.Unparen(call.Args[0])
+		}
+	}
+	return GetValueFromExp(value, pass)
+}
+
+// LiteralField returns the value of field in the keyed struct literal lit, or nil if it is not set
+func LiteralField(lit *ast.CompositeLit, field string) ast.Expr {
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok && keyName(kv.Key) == field {
+			return ast.Unparen(kv.Value)
+		}
+	}
+	return nil
+}
+
 func ResolveParamFromName(name string, sig *types.Signature, param *ast.CallExpr, pass *analysis.Pass) string {
 	// First get the pos for the arg
 	pos, err := GetParamPos(sig, name)
@@ -61,6 +136,12 @@ func GetValueFromExp(exp ast.Expr, pass *analysis.Pass) string {
 	// otherwise this could be double work for nothing. That way we also don't need to pass a Pass to so many
 	// funcs here, andinstead can stick to packages.TypesInfo
 	info := pass.TypesInfo
+	// Constant durations, as in 5*time.Second, are formatted as durations rather than nanoseconds
+	if tv, ok := info.Types[exp]; ok && tv.Value != nil && isNamed(tv.Type, "time", "Duration") {
+		if d, exact := constant.Int64Val(tv.Value); exact {
+			return strconv.Quote(time.Duration(d).String())
+		}
+	}
 	switch node := exp.(type) {
 	case *ast.BasicLit: // i.e. "/thepath"
 		return node.Value
@@ -113,6 +194,8 @@ func GetValueFromExp(exp ast.Expr, pass *analysis.Pass) string {
 			vals = vals + " " + val
 		}
 		return vals
+	case *ast.CallExpr: // i.e. fmt.Sprintf("/users/%s", id)
+		return resolveSprintf(node, pass)
 	case *ast.BinaryExpr: // i.e. base+"/getUser"
 		left := GetValueFromExp(node.X, pass)
 		right := GetValueFromExp(node.Y, pass)
@@ -129,6 +212,113 @@ func GetValueFromExp(exp ast.Expr, pass *analysis.Pass) string {
 	return ""
 }
 
+// resolveSprintf resolves a call to fmt.Sprintf with a constant format as the concatenation of the formatted constant
+// parts and the values of the other args, as done for binary expressions. Returns an empty string for other calls
+func resolveSprintf(ce *ast.CallExpr, pass *analysis.Pass) string {
+	info := pass.TypesInfo
+	fn, ok := typeutil.Callee(info, ce).(*types.Func)
+	if !ok || fn.FullName() != "fmt.Sprintf" || len(ce.Args) == 0 {
+		return ""
+	}
+	tv, ok := info.Types[ce.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return ""
+	}
+	format, args := constant.StringVal(tv.Value), ce.Args[1:]
+
+	var res, lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			res.WriteString(strconv.Quote(lit.String()))
+			lit.Reset()
+		}
+	}
+	unresolved := func(val string) {
+		if val == "" {
+			val = "<could not resolve>"
+		}
+		flush()
+		res.WriteString(val)
+	}
+	argNum := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			lit.WriteByte(format[i])
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.", format[j]) >= 0 {
+			j++
+		}
+		// Explicit arg indexes, as in %[2]s, set the arg of the verb and of the verbs following it
+		if j < len(format) && format[j] == '[' {
+			end := strings.IndexByte(format[j:], ']')
+			if end < 0 {
+				lit.WriteString(format[i:])
+				break
+			}
+			if n, err := strconv.Atoi(format[j+1 : j+end]); err == nil {
+				argNum = n - 1
+			}
+			j += end + 1
+			for j < len(format) && strings.IndexByte("+-# 0123456789.", format[j]) >= 0 {
+				j++
+			}
+		}
+		if j >= len(format) {
+			lit.WriteString(format[i:])
+			break
+		}
+		verb := format[i:j] + format[j:j+1]
+		i = j
+		if format[j] == '%' {
+			lit.WriteByte('%')
+			continue
+		}
+		if argNum < 0 || argNum >= len(args) {
+			unresolved("")
+			continue
+		}
+		arg := args[argNum]
+		argNum++
+		// The index is left out of the verb, as the arg is passed on its own
+		if k := strings.IndexByte(verb, '['); k >= 0 {
+			verb = verb[:k] + verb[strings.IndexByte(verb, ']')+1:]
+		}
+		if atv, ok := info.Types[arg]; ok && atv.Value != nil {
+			lit.WriteString(fmt.Sprintf(verb, constantArg(atv.Value)))
+			continue
+		}
+		val := GetValueFromExp(arg, pass)
+		if uq, err := strconv.Unquote(val); err == nil && (format[j] == 's' || format[j] == 'v') {
+			lit.WriteString(uq)
+			continue
+		}
+		unresolved(val)
+	}
+	flush()
+	return res.String()
+}
+
+// constantArg returns the Go value of a constant as passed to fmt. Ints that do not fit an int64 and complex numbers
+// are passed as their exact string
+func constantArg(v constant.Value) any {
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Bool:
+		return constant.BoolVal(v)
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return i
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f
+	}
+	return v.ExactString()
+}
+
 // ResolvePackageFromIdent TODO: This may be useful to get receiver type of func
 // Also, wrong name, its from an Expr, not from Idt, technically
 func ResolvePackageFromIdent(expr ast.Expr, info *types.Info) (*types.Package, error) {
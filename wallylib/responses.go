@@ -0,0 +1,309 @@
+package wallylib
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Encodings of response bodies
+const (
+	JSONEncoding = "json"
+	XMLEncoding  = "xml"
+)
+
+// Responses describes what a handler writes back
+type Responses struct {
+	StatusCodes  []int          `json:",omitempty"`
+	ContentTypes []string       `json:",omitempty"`
+	Bodies       []ResponseBody `json:",omitempty"`
+}
+
+// ResponseBody is a Go value encoded into a response
+type ResponseBody struct {
+	Encoding string
+	Type     string
+	// Status is set when the status code is written along with the body, as in c.JSON(http.StatusOK, v)
+	Status int             `json:",omitempty"`
+	Fields []ResponseField `json:",omitempty"`
+}
+
+// ResponseField is an exported field of a struct encoded into a response
+type ResponseField struct {
+	Name string
+	Type string
+	// Key is the name of the field in the encoded body, taken from its json or xml tag
+	Key string
+}
+
+type responseWriter struct {
+	// status is the position of the argument holding the status code, or -1
+	status int
+	// body is the position of the argument holding the encoded value, or -1
+	body        int
+	encoding    string
+	contentType string
+	// fixedStatus is the status code written by functions such as http.NotFound
+	fixedStatus int
+}
+
+// Response writers by function full name. Functions from packages with a major version suffix are also matched
+// without it
+var responseWriters = map[string]responseWriter{
+	"(net/http.ResponseWriter).WriteHeader": {status: 0, body: -1},
+	"net/http.Error":                        {status: 2, body: -1, contentType: "text/plain; charset=utf-8"},
+	"net/http.Redirect":                     {status: 3, body: -1},
+	"net/http.NotFound":                     {status: -1, body: -1, fixedStatus: 404, contentType: "text/plain; charset=utf-8"},
+	"(*encoding/json.Encoder).Encode":       {status: -1, body: 0, encoding: JSONEncoding},
+	"(*encoding/xml.Encoder).Encode":        {status: -1, body: 0, encoding: XMLEncoding},
+
+	"(*github.com/gin-gonic/gin.Context).JSON":                {status: 0, body: 1, encoding: JSONEncoding, contentType: "application/json"},
+	"(*github.com/gin-gonic/gin.Context).IndentedJSON":        {status: 0, body: 1, encoding: JSONEncoding, contentType: "application/json"},
+	"(*github.com/gin-gonic/gin.Context).PureJSON":            {status: 0, body: 1, encoding: JSONEncoding, contentType: "application/json"},
+	"(*github.com/gin-gonic/gin.Context).XML":                 {status: 0, body: 1, encoding: XMLEncoding, contentType: "application/xml"},
+	"(*github.com/gin-gonic/gin.Context).String":              {status: 0, body: -1, contentType: "text/plain"},
+	"(*github.com/gin-gonic/gin.Context).Status":              {status: 0, body: -1},
+	"(*github.com/gin-gonic/gin.Context).AbortWithStatus":     {status: 0, body: -1},
+	"(*github.com/gin-gonic/gin.Context).AbortWithStatusJSON": {status: 0, body: 1, encoding: JSONEncoding, contentType: "application/json"},
+	"(*github.com/gin-gonic/gin.Context).Redirect":            {status: 0, body: -1},
+
+	"(github.com/labstack/echo.Context).JSON":      {status: 0, body: 1, encoding: JSONEncoding, contentType: "application/json"},
+	"(github.com/labstack/echo.Context).XML":       {status: 0, body: 1, encoding: XMLEncoding, contentType: "application/xml"},
+	"(github.com/labstack/echo.Context).String":    {status: 0, body: -1, contentType: "text/plain"},
+	"(github.com/labstack/echo.Context).NoContent": {status: 0, body: -1},
+	"(github.com/labstack/echo.Context).Redirect":  {status: 0, body: -1},
+}
+
+type responseResolver struct {
+	res         *Responses
+	statusSeen  map[int]bool
+	contentSeen map[string]bool
+	bodySeen    map[string]bool
+}
+
+// ResolveResponses returns what the handler passed to the route registration ce writes back: the status codes passed
+// to WriteHeader, http.Error or framework helpers, the content types set on the response headers, and the types
+// encoded into the body with their fields. Calls to functions found by source are followed. Returns nil if the
+// handler could not be found
+func ResolveResponses(ce *ast.CallExpr, pass *analysis.Pass, source FuncSource) *Responses {
+	r := &responseResolver{
+		res:         &Responses{},
+		statusSeen:  make(map[int]bool),
+		contentSeen: make(map[string]bool),
+		bodySeen:    make(map[string]bool),
+	}
+	if !walkHandler(ce, pass, source, r.visit) {
+		return nil
+	}
+	sort.Ints(r.res.StatusCodes)
+	return r.res
+}
+
+func (r *responseResolver) visit(node ast.Node, scope *walkScope) bool {
+	ce, ok := node.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn := funcObj(ce.Fun, scope.pass.TypesInfo)
+	if fn == nil {
+		return false
+	}
+
+	if isContentTypeHeader(fn, ce, scope) {
+		r.addContentType(inputName(ce.Args[1], scope))
+		return true
+	}
+
+	rw, ok := lookupResponseWriter(fn)
+	if !ok {
+		return false
+	}
+	// Encoders write responses only when created for the response writer, as in json.NewEncoder(w)
+	if rw.encoding != "" && rw.contentType == "" && !encodesResponse(ce, scope) {
+		return true
+	}
+
+	status := rw.fixedStatus
+	if rw.status >= 0 && rw.status < len(ce.Args) {
+		status = statusCode(ce.Args[rw.status], scope)
+	}
+	if status > 0 {
+		r.addStatus(status)
+	}
+	if rw.contentType != "" {
+		r.addContentType(rw.contentType)
+	}
+	if rw.body >= 0 && rw.body < len(ce.Args) {
+		r.addBody(rw.encoding, scope.typeOf(ce.Args[rw.body]), status)
+	}
+	return true
+}
+
+func (r *responseResolver) addStatus(status int) {
+	if r.statusSeen[status] {
+		return
+	}
+	r.statusSeen[status] = true
+	r.res.StatusCodes = append(r.res.StatusCodes, status)
+}
+
+func (r *responseResolver) addContentType(ct string) {
+	if ct == "" || r.contentSeen[ct] {
+		return
+	}
+	r.contentSeen[ct] = true
+	r.res.ContentTypes = append(r.res.ContentTypes, ct)
+}
+
+func (r *responseResolver) addBody(encoding string, t types.Type, status int) {
+	if t == nil {
+		return
+	}
+	body := ResponseBody{Encoding: encoding, Type: decodedType(t), Status: status}
+	key := fmt.Sprintf("%s %s %d", body.Encoding, body.Type, status)
+	if r.bodySeen[key] {
+		return
+	}
+	r.bodySeen[key] = true
+	body.Fields = structFields(t, encoding)
+	r.res.Bodies = append(r.res.Bodies, body)
+}
+
+func lookupResponseWriter(fn *types.Func) (responseWriter, bool) {
+	name := fn.FullName()
+	if rw, ok := responseWriters[name]; ok {
+		return rw, true
+	}
+	if fn.Pkg() != nil {
+		if unversioned := unversionedPath(fn.Pkg().Path()); unversioned != fn.Pkg().Path() {
+			rw, ok := responseWriters[strings.Replace(name, fn.Pkg().Path(), unversioned, 1)]
+			return rw, ok
+		}
+	}
+	return responseWriter{}, false
+}
+
+// isContentTypeHeader reports whether ce sets the Content-Type header of a response, as in
+// w.Header().Set("Content-Type", "application/json")
+func isContentTypeHeader(fn *types.Func, ce *ast.CallExpr, scope *walkScope) bool {
+	name := fn.FullName()
+	if (name != "(net/http.Header).Set" && name != "(net/http.Header).Add") || len(ce.Args) != 2 {
+		return false
+	}
+	sel, ok := ast.Unparen(ce.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if _, isCall := ast.Unparen(sel.X).(*ast.CallExpr); !isCall {
+		return false
+	}
+	return strings.EqualFold(inputName(ce.Args[0], scope), "Content-Type")
+}
+
+// encodesResponse reports whether the receiver of an Encode call is an encoder created for an http.ResponseWriter.
+// Encoders held in variables are assumed to be
+func encodesResponse(ce *ast.CallExpr, scope *walkScope) bool {
+	sel, ok := ast.Unparen(ce.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	newEnc, ok := ast.Unparen(sel.X).(*ast.CallExpr)
+	if !ok {
+		return true
+	}
+	if len(newEnc.Args) != 1 {
+		return false
+	}
+	return isResponseWriterType(scope.typeOf(newEnc.Args[0]))
+}
+
+func isResponseWriterType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	named, ok := t.(*types.Named)
+	if ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "net/http" && named.Obj().Name() == "ResponseWriter" {
+		return true
+	}
+	return hasMethod(t, "WriteHeader") && hasMethod(t, "Header")
+}
+
+// statusCode returns the value of a constant status code expression, such as http.StatusOK, or 0. Parameters are
+// resolved to the arguments passed for them, as in writeJSON(w, http.StatusOK, v)
+func statusCode(expr ast.Expr, scope *walkScope) int {
+	expr, scope = scope.resolve(expr)
+	tv, ok := scope.pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0
+	}
+	v, ok := constant.Int64Val(tv.Value)
+	if !ok {
+		return 0
+	}
+	return int(v)
+}
+
+// structFields returns the exported fields of the struct t, or of the elements of t for slices, arrays, maps and
+// pointers, along with the keys they are encoded with. Self-referential types, as in type Tree map[string]Tree, have
+// no fields
+func structFields(t types.Type, encoding string) []ResponseField {
+	seen := map[types.Type]bool{}
+	for !seen[t] {
+		seen[t] = true
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+			continue
+		case *types.Slice:
+			t = u.Elem()
+			continue
+		case *types.Array:
+			t = u.Elem()
+			continue
+		case *types.Map:
+			t = u.Elem()
+			continue
+		case *types.Struct:
+			var fields []ResponseField
+			for i := 0; i < u.NumFields(); i++ {
+				f := u.Field(i)
+				if !f.Exported() {
+					continue
+				}
+				key := f.Name()
+				tag := reflect.StructTag(u.Tag(i)).Get(encoding)
+				if name, _, _ := strings.Cut(tag, ","); name == "-" {
+					continue
+				} else if name != "" {
+					key = name
+				}
+				fields = append(fields, ResponseField{Name: f.Name(), Type: types.TypeString(f.Type(), nil), Key: key})
+			}
+			return fields
+		}
+		return nil
+	}
+	return nil
+}
+
+// unversionedPath strips the major version suffix of a package path, as in github.com/go-chi/chi/v5
+func unversionedPath(path string) string {
+	idx := strings.LastIndex(path, "/v")
+	if idx <= 0 {
+		return path
+	}
+	for _, c := range path[idx+2:] {
+		if c < '0' || c > '9' {
+			return path
+		}
+	}
+	if len(path) == idx+2 {
+		return path
+	}
+	return path[:idx]
+}
@@ -0,0 +1,124 @@
+package wallylib
+
+import (
+	"github.com/hex0punk/wally/indicator"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"strings"
+)
+
+// RPCMethod is a method of an RPC service, along with the route it is served on
+type RPCMethod struct {
+	// FullMethod is the route of the method, as in /helloworld.Greeter/SayHello for gRPC and Connect, or
+	// /twirp/helloworld.Greeter/SayHello for Twirp
+	FullMethod string
+	Service    string
+	Method     string
+	Streaming  bool
+	// Impl is the method of the value registered for the service that serves the RPC. It is the method of the
+	// service interface when the concrete type of the value is not known
+	Impl *types.Func
+}
+
+// PackageSource returns the files and type info of a package, which may be a dependency of the analyzed packages
+type PackageSource func(pkg *types.Package) ([]*ast.File, *types.Info)
+
+// IsRPCConstructor reports whether the function name with signature sig has the shape of a service constructor
+// generated for framework: RegisterXxxServer for gRPC, NewXxxHandler for Connect and NewXxxServer for Twirp
+func IsRPCConstructor(framework, name string, sig *types.Signature) bool {
+	if sig == nil || sig.Recv() != nil {
+		return false
+	}
+	switch framework {
+	case indicator.GRPCFramework:
+		return isGRPCRegistration(name, sig)
+	case indicator.ConnectFramework:
+		return isConnectConstructor(name, sig)
+	case indicator.TwirpFramework:
+		return isTwirpConstructor(name, sig)
+	}
+	return false
+}
+
+// ResolveRPCMethods returns the methods of the service registered or constructed by ce, a call matched by an
+// indicator of framework. Methods are resolved on the concrete type of the service implementation passed to ce.
+// Returns nil if ce does not register a service, as for go-kit servers, or if the service could not be resolved
+func ResolveRPCMethods(framework string, ce *ast.CallExpr, pass *analysis.Pass, source PackageSource) []RPCMethod {
+	fn := funcObj(ce.Fun, pass.TypesInfo)
+	if fn == nil {
+		return nil
+	}
+	switch framework {
+	case indicator.GRPCFramework:
+		return resolveGRPCMethods(fn, ce, pass, source)
+	case indicator.ConnectFramework:
+		if isConnectConstructor(fn.Name(), fn.Type().(*types.Signature)) && len(ce.Args) > 0 {
+			return resolveConnectMethods(fn, pass.TypesInfo.TypeOf(ce.Args[0]))
+		}
+	case indicator.TwirpFramework:
+		if isTwirpConstructor(fn.Name(), fn.Type().(*types.Signature)) && len(ce.Args) > 0 {
+			return resolveTwirpMethods(fn, ce, pass)
+		}
+	case indicator.GQLGenFramework:
+		return resolveGraphQLResolvers(ce, pass, source)
+	}
+	return nil
+}
+
+// lookupImpl returns the method name of impl, or of iface if impl is not known or is an interface itself
+func lookupImpl(impl, iface types.Type, name string) *types.Func {
+	if impl == nil || (types.IsInterface(impl) && iface != nil) {
+		impl = iface
+	}
+	if impl == nil {
+		return nil
+	}
+	obj, _, _ := types.LookupFieldOrMethod(impl, true, nil, name)
+	m, _ := obj.(*types.Func)
+	return m
+}
+
+// serviceName returns the name of the service of a generated constructor, as in Greeter for RegisterGreeterServer
+func serviceName(name, prefix, suffix string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+}
+
+// interfaceMethods returns the methods of the interface t, or nil if t is not an interface
+func interfaceMethods(t types.Type) []*types.Func {
+	iface, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	var res []*types.Func
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Exported() {
+			res = append(res, iface.Method(i))
+		}
+	}
+	return res
+}
+
+// stringConst returns the value of the string constant name declared in pkg
+func stringConst(pkg *types.Package, name string) (string, bool) {
+	if pkg == nil {
+		return "", false
+	}
+	c, ok := pkg.Scope().Lookup(name).(*types.Const)
+	if !ok || c.Val().Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(c.Val()), true
+}
+
+func isNamed(t types.Type, pkgPath, name string) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return unversionedPath(named.Obj().Pkg().Path()) == pkgPath && named.Obj().Name() == name
+}
@@ -0,0 +1,70 @@
+package wallylib
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"strings"
+)
+
+const twirpPkg = "github.com/twitchtv/twirp"
+
+// isTwirpConstructor reports whether a function is a generated NewXxxServer function, which takes the
+// implementation of a service and returns a TwirpServer
+func isTwirpConstructor(name string, sig *types.Signature) bool {
+	if !strings.HasPrefix(name, "New") || !strings.HasSuffix(name, "Server") {
+		return false
+	}
+	params, results := sig.Params(), sig.Results()
+	if params.Len() == 0 || results.Len() != 1 || !types.IsInterface(params.At(0).Type()) {
+		return false
+	}
+	return hasMethod(results.At(0).Type(), "ProtocGenTwirpVersion")
+}
+
+// resolveTwirpMethods returns the methods of the service constructed by ce. Routes are made of the path prefix
+// generated for the service, as in HaberdasherPathPrefix, and the name of each method. Prefixes set with
+// twirp.WithServerPathPrefix replace the default /twirp prefix
+func resolveTwirpMethods(fn *types.Func, ce *ast.CallExpr, pass *analysis.Pass) []RPCMethod {
+	svc := serviceName(fn.Name(), "New", "Server")
+	prefix, ok := stringConst(fn.Pkg(), svc+"PathPrefix")
+	if !ok {
+		return nil
+	}
+	service := strings.Trim(strings.TrimPrefix(prefix, "/twirp"), "/")
+	if custom, ok := twirpPathPrefix(ce, pass); ok {
+		prefix = custom + "/" + service + "/"
+	}
+
+	iface := fn.Type().(*types.Signature).Params().At(0).Type()
+	impl := pass.TypesInfo.TypeOf(ce.Args[0])
+	var res []RPCMethod
+	for _, m := range interfaceMethods(iface) {
+		res = append(res, RPCMethod{
+			FullMethod: prefix + m.Name(),
+			Service:    service,
+			Method:     m.Name(),
+			Impl:       lookupImpl(impl, iface, m.Name()),
+		})
+	}
+	return res
+}
+
+// twirpPathPrefix returns the path prefix passed to twirp.WithServerPathPrefix among the options of ce, if any
+func twirpPathPrefix(ce *ast.CallExpr, pass *analysis.Pass) (string, bool) {
+	for _, arg := range ce.Args[1:] {
+		opt, ok := ast.Unparen(arg).(*ast.CallExpr)
+		if !ok || len(opt.Args) != 1 {
+			continue
+		}
+		fn := funcObj(opt.Fun, pass.TypesInfo)
+		if fn == nil || fn.Pkg() == nil || unversionedPath(fn.Pkg().Path()) != twirpPkg || fn.Name() != "WithServerPathPrefix" {
+			continue
+		}
+		if tv, ok := pass.TypesInfo.Types[opt.Args[0]]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return strings.TrimSuffix(constant.StringVal(tv.Value), "/"), true
+		}
+	}
+	return "", false
+}
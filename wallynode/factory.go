@@ -9,6 +9,11 @@ import (
 
 type WallyNodeFactory struct {
 	CallgraphNodes map[*ssa.Function]*callgraph.Node
+	// RecoveredFuncs holds handlers registered behind middleware that recovers from panics
+	RecoveredFuncs map[*ssa.Function]bool
+	// EntryFuncs maps the functions run by entry points other than routes to their label, as in command: wally map or
+	// job: @every 1m
+	EntryFuncs map[*ssa.Function]string
 }
 
 func NewWallyNodeFactory(callGraphnodes map[*ssa.Function]*callgraph.Node) *WallyNodeFactory {
@@ -24,8 +29,8 @@ func (f *WallyNodeFactory) CreateWallyNode(nodeStr string, caller *callgraph.Nod
 			nodeStr = fmt.Sprintf("Func: %s.[%s] %s", caller.Func.Pkg.Pkg.Name(), caller.Func.Name(), wallylib.GetFormattedPos(caller.Func.Package(), caller.Func.Pos()))
 		} else {
 			fp := wallylib.GetFormattedPos(caller.Func.Package(), site.Pos())
-			recoverable = IsRecoverable(caller, f.CallgraphNodes)
-			nodeStr = GetNodeString(fp, caller, recoverable)
+			recoverable = f.IsRecoverable(caller)
+			nodeStr = f.NodeString(fp, caller, recoverable)
 		}
 	}
 	return WallyNode{
@@ -35,3 +40,20 @@ func (f *WallyNodeFactory) CreateWallyNode(nodeStr string, caller *callgraph.Nod
 		recoverable: recoverable,
 	}
 }
+
+// IsRecoverable reports whether panics in the function of the node are recovered, either by the function itself or
+// by middleware wrapping it when it is registered as a handler
+func (f *WallyNodeFactory) IsRecoverable(node *callgraph.Node) bool {
+	if node == nil || node.Func == nil {
+		return false
+	}
+	return f.RecoveredFuncs[node.Func] || IsRecoverable(node, f.CallgraphNodes)
+}
+
+// NodeString returns the string of the node for the function of s, labeled with the entry point it handles, if any
+func (f *WallyNodeFactory) NodeString(basePos string, s *callgraph.Node, recoverable bool) string {
+	if label := f.EntryFuncs[s.Func]; label != "" {
+		basePos = fmt.Sprintf("(%s) %s", label, basePos)
+	}
+	return GetNodeString(basePos, s, recoverable)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hex0punk/wally/wallylib"
+	"go/token"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/ssa"
 )
@@ -26,6 +27,20 @@ func (n *WallyNode) IsRecoverable() bool {
 	return n.recoverable
 }
 
+// Position returns the position of the call site for the node or, if the node
+// has no site, the position of the function itself
+func (n *WallyNode) Position() token.Position {
+	if n.Caller == nil || n.Caller.Func == nil || n.Caller.Func.Prog == nil {
+		return token.Position{}
+	}
+
+	pos := n.Caller.Func.Pos()
+	if n.Site != nil {
+		pos = n.Site.Pos()
+	}
+	return n.Caller.Func.Prog.Fset.Position(pos)
+}
+
 func GetNodeString(basePos string, s *callgraph.Node, recoverable bool) string {
 	pkg := s.Func.Package()
 	function := s.Func
@@ -68,6 +83,38 @@ func IsRecoverable(s *callgraph.Node, callgraphNodes map[*ssa.Function]*callgrap
 	return false
 }
 
+// RecoversPanics reports whether fn, or any function literal defined in it, defers a call that recovers from panics.
+// It is used for middleware, which usually recover in the handler they return rather than in their own body
+func RecoversPanics(fn *ssa.Function) bool {
+	if fn == nil {
+		return false
+	}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			deferInstr, ok := instr.(*ssa.Defer)
+			if !ok {
+				continue
+			}
+			switch callee := deferInstr.Call.Value.(type) {
+			case *ssa.Function:
+				if containsRecoverCall(callee) {
+					return true
+				}
+			case *ssa.MakeClosure:
+				if closureFn, ok := callee.Fn.(*ssa.Function); ok && containsRecoverCall(closureFn) {
+					return true
+				}
+			}
+		}
+	}
+	for _, af := range fn.AnonFuncs {
+		if RecoversPanics(af) {
+			return true
+		}
+	}
+	return false
+}
+
 func findDeferRecover(fn *ssa.Function, idx int) (bool, error) {
 	visited := make(map[*ssa.Function]bool)
 	return findDeferRecoverRecursive(fn, visited, idx)